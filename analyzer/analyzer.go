@@ -0,0 +1,207 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+// Package analyzer exposes callcheck as a golang.org/x/tools/go/analysis
+// Analyzer, so that it can be run by go vet and other analysis drivers.
+//
+// Rather than loading an entire program at once, each package is summarised
+// into a fact containing the call graph of the functions it declares. When a
+// package is analyzed, the facts of all of its dependencies are combined with
+// its own call graph, and any policy whose rule is rooted in that package is
+// evaluated.
+package analyzer
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/joshdk/callcheck/config"
+	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/policy"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:       "callcheck",
+	Doc:        "check that call chains do not violate policies defined in callcheck.yml",
+	Run:        run,
+	FactTypes:  []analysis.Fact{new(Summary)},
+	ResultType: reflect.TypeOf(map[string]graph.FuncDecl{}),
+}
+
+// Summary is a package fact containing the call graph of every function
+// declared in a package, and of every function in its dependencies that they
+// transitively call.
+type Summary struct {
+	Decls map[string]graph.FuncDecl
+}
+
+func (*Summary) AFact() {}
+
+func (s *Summary) String() string {
+	return fmt.Sprintf("callcheck summary of %d functions", len(s.Decls))
+}
+
+var configFile = config.DefaultFilename
+
+func init() {
+	Analyzer.Flags.StringVar(&configFile, "config", configFile, "path to the callcheck config file")
+}
+
+var (
+	loadOnce sync.Once
+	loaded   *config.Config
+	loadErr  error
+)
+
+// load reads and validates the config file exactly once, regardless of how
+// many packages are analyzed.
+func load() (*config.Config, error) {
+	loadOnce.Do(func() {
+		loaded, loadErr = config.LoadFile(configFile)
+		if loadErr != nil {
+			return
+		}
+
+		if problems := loaded.Validate(); len(problems) != 0 {
+			loaded, loadErr = nil, problems
+		}
+	})
+
+	return loaded, loadErr
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	own := graph.Package(pass.Fset, pass.TypesInfo, pass.Files)
+
+	decls := make(map[string]graph.FuncDecl, len(own))
+	for _, fact := range pass.AllPackageFacts() {
+		for name, decl := range fact.Fact.(*Summary).Decls {
+			decls[name] = decl
+		}
+	}
+
+	// Export the call graph of this package, so that packages which import it
+	// can evaluate chains that pass through it. Analysis drivers only provide
+	// the facts of direct imports, so the parts of the call graphs of our own
+	// dependencies that this package reaches are exported along with it.
+	pass.ExportPackageFact(&Summary{Decls: reachable(decls, own)})
+
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only policies whose rule is rooted in this package are evaluated here,
+	// as every other policy is evaluated in the package that declares it.
+	// Pair, recursion, and deprecation policies may be violated by any
+	// function.
+	var policies []policy.Policy
+	for _, forbidden := range cfg.Forbidden {
+		for name := range own {
+			if forbidden.Pair != nil || forbidden.ForbidRecursion || forbidden.ForbidDeprecated || forbidden.Rule.Matches(name) {
+				policies = append(policies, forbidden)
+				break
+			}
+		}
+	}
+
+	if len(policies) == 0 {
+		return own, nil
+	}
+
+	// Only the functions of this package are being checked, rather than
+	// those of its dependencies.
+	for name, decl := range own {
+		decls[name] = decl
+	}
+
+	graph.MarkInitial(decls, map[string]struct{}{pass.Pkg.Path(): {}})
+
+	for _, forbidden := range policies {
+		for _, violation := range policy.MatchingPaths(decls, forbidden) {
+			// Violations rooted in other packages are reported when those
+			// packages are analyzed.
+			if _, found := own[violation.Name]; !found {
+				continue
+			}
+
+			position := violation.Position
+			if len(violation.Calls) != 0 {
+				position = violation.Calls[0].Position
+			}
+
+			message := fmt.Sprintf("%s violates policy %q", chain(violation), forbidden.Name)
+			if explanation := (policy.Violation{Policy: forbidden, Decl: violation}).Message(); explanation != "" {
+				message += ": " + explanation
+			}
+
+			pass.Reportf(pos(pass, position), "%s", message)
+		}
+	}
+
+	return own, nil
+}
+
+// reachable returns the given functions of a single package, along with every
+// function in decls that they transitively call.
+func reachable(decls map[string]graph.FuncDecl, own map[string]graph.FuncDecl) map[string]graph.FuncDecl {
+	result := make(map[string]graph.FuncDecl, len(own))
+	queue := make([]string, 0, len(own))
+	for name, decl := range own {
+		result[name] = decl
+		queue = append(queue, name)
+	}
+
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, call := range result[name].Calls {
+			if _, found := result[call.Name]; found {
+				continue
+			}
+			if decl, found := decls[call.Name]; found {
+				result[call.Name] = decl
+				queue = append(queue, call.Name)
+			}
+		}
+	}
+
+	return result
+}
+
+// chain formats the first branch of the given violation as a single line.
+func chain(decl policy.Decl) string {
+	names := []string{decl.Name}
+	for len(decl.Calls) != 0 {
+		decl = decl.Calls[0].Decl
+		names = append(names, decl.Name)
+	}
+	return strings.Join(names, " → ")
+}
+
+// pos converts a position formatted by graph back into a token.Pos, provided
+// that it lies within one of the files of the current package.
+func pos(pass *analysis.Pass, position string) token.Pos {
+	filename, line, column, ok := graph.ParsePosition(position)
+	if !ok {
+		return token.NoPos
+	}
+
+	for _, file := range pass.Files {
+		tokFile := pass.Fset.File(file.Pos())
+		if tokFile == nil || tokFile.Name() != filename || line > tokFile.LineCount() {
+			continue
+		}
+
+		return tokFile.LineStart(line) + token.Pos(column-1)
+	}
+
+	return token.NoPos
+}
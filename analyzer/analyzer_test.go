@@ -0,0 +1,52 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+func TestAnalyzer(t *testing.T) {
+	testdata := analysistest.TestData()
+	require.NoError(t, Analyzer.Flags.Set("config", filepath.Join(testdata, "callcheck.yml")))
+
+	// Every violation is expected exactly once, in the package of its root,
+	// even though package a also sees the chains rooted in packages b and c
+	// through their facts.
+	results := analysistest.Run(t, testdata, Analyzer, "a", "b", "c")
+	require.Len(t, results, 3)
+
+	// The facts of package a's dependencies summarise the functions that
+	// they declare.
+	summaries := make(map[string]map[string]graph.FuncDecl)
+	for _, result := range results {
+		if result.Action.Package.PkgPath != "a" {
+			continue
+		}
+
+		for _, fact := range result.Action.AllPackageFacts() {
+			summaries[fact.Package.Path()] = fact.Fact.(*Summary).Decls
+		}
+	}
+
+	require.Contains(t, summaries, "b")
+	require.Contains(t, summaries["b"], "b.Helper")
+	assert.Equal(t, "c.Exit", summaries["b"]["b.Helper"].Calls[0].Name)
+
+	// Package b also carries the functions of package c that it calls, as
+	// analysis drivers only provide the facts of direct imports.
+	assert.Contains(t, summaries["b"], "c.Exit")
+
+	require.Contains(t, summaries, "c")
+	require.Contains(t, summaries["c"], "c.Exit")
+	assert.Equal(t, "os.Exit", summaries["c"]["c.Exit"].Calls[0].Name)
+}
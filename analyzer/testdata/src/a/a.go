@@ -0,0 +1,11 @@
+package a // want package:"callcheck summary of [0-9]+ functions"
+
+import "b"
+
+func Run() {
+	b.Helper() // want `^a\.Run → b\.Helper → c\.Exit → os\.Exit violates policy "no-exit"`
+}
+
+func Safe() {
+	b.Log()
+}
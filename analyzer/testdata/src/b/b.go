@@ -0,0 +1,14 @@
+package b // want package:"callcheck summary of [0-9]+ functions"
+
+import (
+	"c"
+	"fmt"
+)
+
+func Helper() {
+	c.Exit(1) // want `^b\.Helper → c\.Exit → os\.Exit violates policy "no-exit"`
+}
+
+func Log() {
+	fmt.Println("b")
+}
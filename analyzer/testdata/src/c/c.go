@@ -0,0 +1,7 @@
+package c // want package:"callcheck summary of [0-9]+ functions"
+
+import "os"
+
+func Exit(code int) {
+	os.Exit(code) // want `^c\.Exit → os\.Exit violates policy "no-exit"`
+}
@@ -0,0 +1,19 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+// Command vettool runs callcheck under go vet:
+//
+//	go build -o callcheck-vet github.com/joshdk/callcheck/analyzer/vettool
+//	go vet -vettool=$(pwd)/callcheck-vet -callcheck.config=$(pwd)/callcheck.yml ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/joshdk/callcheck/analyzer"
+)
+
+func main() {
+	unitchecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVettool(t *testing.T) {
+	testdata, err := filepath.Abs(filepath.Join("..", "testdata"))
+	require.NoError(t, err)
+
+	tool := filepath.Join(t.TempDir(), "callcheck-vet")
+	output, err := exec.Command("go", "build", "-o", tool, ".").CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	vet := exec.Command("go", "vet", "-vettool="+tool, "-callcheck.config="+filepath.Join(testdata, "callcheck.yml"), "a")
+	vet.Env = append(os.Environ(), "GOPATH="+testdata, "GO111MODULE=off", "GOFLAGS=")
+	output, err = vet.CombinedOutput()
+
+	// Only the violation rooted in package a is reported, even though go vet
+	// also analyzes its dependencies for their facts.
+	require.Error(t, err)
+	assert.Equal(t, 1, strings.Count(string(output), "violates policy"), string(output))
+	assert.Contains(t, string(output), `a.go:6:2: a.Run → b.Helper → c.Exit → os.Exit violates policy "no-exit"`)
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+// Package cache persists the call graph of individual packages on disk, so
+// that unchanged packages do not need to be type checked again.
+//
+// Each package is stored under a key derived from the contents of its source
+// files, the Go version, the build configuration, and the keys of every
+// package it imports. Changing any of these produces a different key, so
+// stale entries are never used and a change to one package invalidates every
+// package that depends on it.
+package cache
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+// Cache is a directory of cached package call graphs.
+type Cache struct {
+	dir string
+}
+
+// Open returns a cache stored in the given directory, creating the directory
+// if needed.
+func Open(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Cache{dir}, nil
+}
+
+// DefaultDir returns the directory used for caching when none is specified.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "callcheck"), nil
+}
+
+func (c *Cache) filename(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".gob")
+}
+
+// Get returns the call graph stored under the given key. The returned bool is
+// false if no usable entry exists.
+func (c *Cache) Get(key string) (map[string]graph.FuncDecl, bool) {
+	file, err := os.Open(c.filename(key))
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	var decls map[string]graph.FuncDecl
+	if err := gob.NewDecoder(file).Decode(&decls); err != nil {
+		return nil, false
+	}
+
+	return decls, true
+}
+
+// Put stores the given call graph under the given key. Entries are written to
+// a temporary file first, so that concurrent runs never observe a partially
+// written entry.
+func (c *Cache) Put(key string, decls map[string]graph.FuncDecl) error {
+	filename := c.filename(key)
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filename), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := gob.NewEncoder(file).Encode(decls); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), filename)
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// version is mixed into every key, and must be changed whenever the format of
+// cached call graphs changes.
+const version = "9"
+
+// Keys computes a cache key for every package transitively imported by the
+// packages named by paths, as resolved relative to the directory cwd. Keys are
+// indexed by the import path that the package resolves to. Packages that could
+// not be located, and packages that depend on them, are omitted. If tests is
+// true, the packages named by paths are keyed as if loaded with their
+// _test.go files.
+func Keys(ctxt *build.Context, cwd string, paths []string, tests bool) map[string]string {
+	k := keyer{
+		ctxt:     ctxt,
+		keys:     make(map[string]string),
+		resolved: make(map[[2]string]string),
+		tests:    make(map[string]bool),
+	}
+
+	// Resolve the initial packages up front, so that they are keyed with
+	// their tests even if they are first encountered as a dependency.
+	if tests {
+		for _, path := range paths {
+			if pkg, err := ctxt.Import(path, cwd, 0); err == nil {
+				k.tests[pkg.ImportPath] = true
+			}
+		}
+	}
+
+	for _, path := range paths {
+		k.key(path, cwd)
+	}
+
+	return k.keys
+}
+
+type keyer struct {
+	ctxt *build.Context
+
+	// keys maps import paths to their key. An empty key marks a package
+	// that could not be keyed.
+	keys map[string]string
+
+	// resolved memoizes the import path that an import, from a given
+	// directory, resolves to.
+	resolved map[[2]string]string
+
+	// tests records the import paths of packages that are loaded along with
+	// their _test.go files.
+	tests map[string]bool
+}
+
+// key returns the key of the package imported as path from the directory dir,
+// or an empty string if the package could not be keyed.
+func (k *keyer) key(path string, dir string) string {
+	if path == "C" || path == "unsafe" {
+		return path
+	}
+
+	if importPath, found := k.resolved[[2]string{path, dir}]; found {
+		return k.keys[importPath]
+	}
+
+	pkg, err := k.ctxt.Import(path, dir, 0)
+	if err != nil {
+		k.resolved[[2]string{path, dir}] = ""
+		return ""
+	}
+
+	k.resolved[[2]string{path, dir}] = pkg.ImportPath
+
+	if key, found := k.keys[pkg.ImportPath]; found {
+		return key
+	}
+
+	// Guard against import cycles, which would otherwise recurse forever.
+	k.keys[pkg.ImportPath] = ""
+
+	hash := sha256.New()
+
+	fmt.Fprintf(hash, "version %s\n", version)
+	fmt.Fprintf(hash, "go %s\n", runtime.Version())
+	fmt.Fprintf(hash, "target %s/%s cgo=%t\n", k.ctxt.GOOS, k.ctxt.GOARCH, k.ctxt.CgoEnabled)
+	fmt.Fprintf(hash, "tags %s\n", strings.Join(k.ctxt.BuildTags, ","))
+	fmt.Fprintf(hash, "package %s tests=%t\n", pkg.ImportPath, k.tests[pkg.ImportPath])
+
+	files := append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...)
+	imports := append([]string{}, pkg.Imports...)
+
+	if k.tests[pkg.ImportPath] {
+		files = append(append(files, pkg.TestGoFiles...), pkg.XTestGoFiles...)
+		imports = append(append(imports, pkg.TestImports...), pkg.XTestImports...)
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		filename := filepath.Join(pkg.Dir, file)
+		if err := hashFile(hash, filename); err != nil {
+			return ""
+		}
+	}
+
+	sort.Strings(imports)
+
+	for _, imp := range imports {
+		// Test packages may import the package under test.
+		if imp == pkg.ImportPath {
+			continue
+		}
+
+		depKey := k.key(imp, pkg.Dir)
+		if depKey == "" {
+			return ""
+		}
+		fmt.Fprintf(hash, "import %s %s\n", imp, depKey)
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	k.keys[pkg.ImportPath] = key
+
+	return key
+}
+
+func hashFile(w io.Writer, filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "file %s %x\n", filename, hash.Sum(nil))
+	return err
+}
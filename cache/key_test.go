@@ -0,0 +1,91 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cache
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+func TestKeys(t *testing.T) {
+	gopath := t.TempDir()
+
+	write := func(name string, body string) {
+		filename := filepath.Join(gopath, "src", name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, os.WriteFile(filename, []byte(body), 0644))
+	}
+
+	write("a/a.go", "package a\n\nimport \"b\"\n\nfunc A() { b.B() }\n")
+	write("b/b.go", "package b\n\nfunc B() {}\n")
+	write("c/c.go", "package c\n\nfunc C() {}\n")
+
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
+	ctxt.JoinPath = filepath.Join
+
+	cwd := filepath.Join(gopath, "src")
+
+	before := Keys(&ctxt, cwd, []string{"a", "c"}, false)
+	require.NotEmpty(t, before["a"])
+	require.NotEmpty(t, before["b"])
+	require.NotEmpty(t, before["c"])
+
+	// Keys are stable when nothing has changed.
+	assert.Equal(t, before, Keys(&ctxt, cwd, []string{"a", "c"}, false))
+
+	// Changing a dependency changes the key of every package that imports it.
+	write("b/b.go", "package b\n\nfunc B() { println() }\n")
+	after := Keys(&ctxt, cwd, []string{"a", "c"}, false)
+
+	assert.NotEqual(t, before["a"], after["a"])
+	assert.NotEqual(t, before["b"], after["b"])
+	assert.Equal(t, before["c"], after["c"])
+
+	// Changing the build configuration changes every key.
+	ctxt.BuildTags = []string{"integration"}
+	tagged := Keys(&ctxt, cwd, []string{"c"}, false)
+	assert.NotEqual(t, after["c"], tagged["c"])
+
+	// Loading with tests changes the keys of the initial packages only.
+	ctxt.BuildTags = nil
+	tested := Keys(&ctxt, cwd, []string{"a"}, true)
+	assert.NotEqual(t, after["a"], tested["a"])
+	assert.Equal(t, after["b"], tested["b"])
+}
+
+func TestCache(t *testing.T) {
+	store, err := Open(t.TempDir())
+	require.NoError(t, err)
+
+	key := "0123456789abcdef"
+
+	_, found := store.Get(key)
+	assert.False(t, found)
+
+	decls := map[string]graph.FuncDecl{
+		"a.A": {
+			Name:     "a.A",
+			Package:  "a",
+			Position: "a.go:5:1",
+			Calls: []graph.FuncCall{
+				{Name: "b.B", Package: "b", Position: "a.go:5:12"},
+			},
+		},
+	}
+
+	require.NoError(t, store.Put(key, decls))
+
+	actual, found := store.Get(key)
+	assert.True(t, found)
+	assert.Equal(t, decls, actual)
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"flag"
+	"go/build"
+	"strings"
+
+	"github.com/joshdk/callcheck/config"
+	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/policy"
+)
+
+// buildFlags holds the flags that select the build configuration used when
+// loading packages.
+type buildFlags struct {
+	tags   string
+	goos   string
+	goarch string
+	tests  bool
+}
+
+func (b *buildFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&b.tags, "tags", "", "comma separated list of build tags to apply")
+	flags.StringVar(&b.goos, "goos", "", "target operating system, overriding GOOS")
+	flags.StringVar(&b.goarch, "goarch", "", "target architecture, overriding GOARCH")
+	flags.BoolVar(&b.tests, "tests", false, "include _test.go files and test packages")
+}
+
+// options returns the load options selected by the flags, taking the build
+// matrix from the given config if no build configuration flags were set.
+func (b *buildFlags) options(cfg *config.Config) loadOptions {
+	return loadOptions{
+		targets: b.targets(cfg),
+		tests:   b.tests,
+	}
+}
+
+// targets returns the build configurations to load. A configuration given by
+// flags takes precedence over the matrix in the config file, if any.
+func (b *buildFlags) targets(cfg *config.Config) []config.Target {
+	if b.tags == "" && b.goos == "" && b.goarch == "" && cfg != nil && len(cfg.Matrix) != 0 {
+		return cfg.Matrix
+	}
+
+	target := config.Target{
+		GOOS:   b.goos,
+		GOARCH: b.goarch,
+	}
+
+	if b.tags != "" {
+		target.Tags = strings.Split(b.tags, ",")
+	}
+
+	return []config.Target{target}
+}
+
+// buildContext returns a build context for the given target, with any unset
+// fields taken from the default build context.
+func buildContext(target config.Target) *build.Context {
+	ctxt := build.Default
+
+	if target.GOOS != "" {
+		ctxt.GOOS = target.GOOS
+	}
+
+	if target.GOARCH != "" {
+		ctxt.GOARCH = target.GOARCH
+	}
+
+	// Cgo is only supported when building for the host.
+	if ctxt.GOOS != build.Default.GOOS || ctxt.GOARCH != build.Default.GOARCH {
+		ctxt.CgoEnabled = false
+	}
+
+	ctxt.BuildTags = append(append([]string{}, build.Default.BuildTags...), target.Tags...)
+
+	return &ctxt
+}
+
+// configurations returns the build configurations in which every function and
+// call of the given violation exist. A nil result means that the violation is
+// not limited to any configuration, while an empty non-nil result means that
+// there is no single configuration in which the violation occurs.
+func configurations(decl policy.Decl, callGraph map[string]graph.FuncDecl) []string {
+	var configs []string
+
+	restrict := func(labels []string) {
+		if len(labels) == 0 {
+			return
+		}
+
+		if configs == nil {
+			configs = append([]string{}, labels...)
+			return
+		}
+
+		var kept []string
+		for _, config := range configs {
+			for _, label := range labels {
+				if config == label {
+					kept = append(kept, config)
+					break
+				}
+			}
+		}
+		configs = append([]string{}, kept...)
+	}
+
+	var walk func(policy.Decl)
+	walk = func(decl policy.Decl) {
+		fn := callGraph[decl.Name]
+		restrict(fn.Configs)
+
+		for _, call := range decl.Calls {
+			if call.Index < len(fn.Calls) {
+				restrict(fn.Calls[call.Index].Configs)
+			}
+			walk(call.Decl)
+		}
+	}
+
+	walk(decl)
+
+	return configs
+}
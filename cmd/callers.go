@@ -0,0 +1,63 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/policy"
+)
+
+// callersCmd prints the tree of functions that call the named function,
+// following call sites backwards up to the given depth.
+func callersCmd(args []string) error {
+	return neighboursCmd("callers", args, graph.Callers)
+}
+
+// calleesCmd prints the tree of functions called by the named function,
+// following calls forwards up to the given depth.
+func calleesCmd(args []string) error {
+	return neighboursCmd("callees", args, graph.Callees)
+}
+
+func neighboursCmd(name string, args []string, index func(map[string]graph.FuncDecl) map[string][]graph.FuncCall) error {
+	var (
+		depth int
+		build buildFlags
+	)
+
+	flags := flag.NewFlagSet("callcheck "+name, flag.ContinueOnError)
+	flags.IntVar(&depth, "depth", 1, "maximum number of calls to follow, or -1 for no limit")
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() < 1 {
+		return fmt.Errorf("usage: callcheck %s [--depth N] <func> [packages]", name)
+	}
+
+	decls, err := load(flags.Args()[1:], build.options(nil))
+	if err != nil {
+		return err
+	}
+
+	edges := index(decls)
+
+	roots := graph.Expand(flags.Arg(0), decls, true)
+	if len(roots) == 0 {
+		return errors.New("no functions matched")
+	}
+
+	for _, root := range roots {
+		fmt.Println(policy.Neighbours(root, decls, edges, depth))
+	}
+
+	return nil
+}
@@ -6,46 +6,96 @@ package cmd
 
 import (
 	"errors"
-	"go/build"
-
-	"github.com/kisielk/gotool"
-	"golang.org/x/tools/go/loader"
+	"flag"
+	"fmt"
+	"os"
 
 	"github.com/joshdk/callcheck/config"
-	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/gitdiff"
 )
 
+// commands maps the name of each subcommand to its implementation. Arguments
+// that do not start with a subcommand name are treated as packages to check.
+var commands = map[string]func([]string) error{
+	"callees":     calleesCmd,
+	"callers":     callersCmd,
+	"config":      configCmd,
+	"cycles":      cyclesCmd,
+	"graph":       graphCmd,
+	"metrics":     metricsCmd,
+	"panics":      panicsCmd,
+	"test":        testCmd,
+	"unreachable": unreachableCmd,
+	"why":         whyCmd,
+}
+
 func Cmd(args []string) error {
-	if len(args) == 0 {
-		args = []string{"./..."}
+	if len(args) > 0 {
+		if command, found := commands[args[0]]; found {
+			return command(args[1:])
+		}
 	}
 
-	checkCfg, err := config.Load()
-	if err != nil {
+	return check(args)
+}
+
+func check(args []string) error {
+	var (
+		diffBase string
+		format   string
+		build    buildFlags
+	)
+
+	flags := flag.NewFlagSet("callcheck", flag.ContinueOnError)
+	flags.StringVar(&diffBase, "diff-base", "", "only report violations that touch lines changed since this git revision")
+	flags.StringVar(&format, "format", "text", "output format, one of text, dot, or json")
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
 		return err
 	}
 
-	paths := gotool.ImportPaths(args)
+	args = flags.Args()
 
-	cfg := loader.Config{
-		Build: &build.Default,
+	report, found := reportFormats[format]
+	if !found {
+		return fmt.Errorf("unknown output format %q", format)
 	}
 
-	if _, err := cfg.FromArgs(paths, false); err != nil {
+	checkCfg, err := config.Load()
+	if err != nil {
 		return err
 	}
 
-	program, err := cfg.Load()
+	if problems := checkCfg.Validate(); len(problems) != 0 {
+		return problems
+	}
+
+	decls, err := load(args, build.options(checkCfg))
 	if err != nil {
 		return err
 	}
 
-	decls, err := graph.Program(program)
-	if err != nil {
+	// Rules that never match anything are likely typos, so warn about them
+	// without failing the run.
+	for _, warning := range checkCfg.Resolve(decls) {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	var changes gitdiff.Changes
+	if diffBase != "" {
+		if changes, err = gitdiff.Diff(diffBase); err != nil {
+			return err
+		}
+	}
+
+	results := violations(decls, checkCfg, changes)
+
+	if err := report(results); err != nil {
 		return err
 	}
 
-	if violations(decls, checkCfg) {
+	if len(results) != 0 {
 		return errors.New("policy violations found")
 	}
 
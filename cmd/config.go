@@ -0,0 +1,86 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/joshdk/callcheck/config"
+)
+
+func configCmd(args []string) error {
+	switch {
+	case len(args) > 0 && args[0] == "validate":
+		return validate(args[1:])
+	case len(args) > 0 && args[0] == "bundles":
+		return bundles()
+	default:
+		return errors.New("usage: callcheck config validate [packages] | callcheck config bundles")
+	}
+}
+
+// bundles prints the name of every built-in bundle, along with the policies
+// that it contains, in the form used by extends.
+func bundles() error {
+	for _, name := range config.Builtins() {
+		fmt.Printf("builtin:%s\n", name)
+
+		cfg, err := config.LoadBuiltin(name)
+		if err != nil {
+			return err
+		}
+
+		for _, forbidden := range cfg.Forbidden {
+			fmt.Printf("  %s: %s\n", forbidden.Name, forbidden.Description)
+		}
+	}
+
+	return nil
+}
+
+// validate checks the config file for malformed, duplicate, or unresolvable
+// policies, and reports the position of every problem found.
+func validate(args []string) error {
+	var build buildFlags
+
+	flags := flag.NewFlagSet("callcheck config validate", flag.ContinueOnError)
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if problems, ok := err.(config.Problems); ok {
+		return reportProblems(problems)
+	} else if err != nil {
+		return err
+	}
+
+	problems := cfg.Validate()
+
+	decls, err := load(flags.Args(), build.options(cfg))
+	if err != nil {
+		return err
+	}
+
+	problems = append(problems, cfg.Resolve(decls)...)
+
+	return reportProblems(problems)
+}
+
+func reportProblems(problems config.Problems) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+
+	return fmt.Errorf("%d problems found in %s", len(problems), config.DefaultFilename)
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/policy"
+)
+
+// jsonCycle is a single cycle of calls, as written by cyclesCmd.
+type jsonCycle struct {
+	Functions []string    `json:"functions"`
+	Cycle     policy.Decl `json:"cycle"`
+}
+
+// cyclesCmd prints every cycle of calls between functions, including
+// functions that call themselves. Only cycles that pass through the named
+// packages are printed, unless --all is given.
+func cyclesCmd(args []string) error {
+	var (
+		all    bool
+		format string
+		build  buildFlags
+	)
+
+	flags := flag.NewFlagSet("callcheck cycles", flag.ContinueOnError)
+	flags.BoolVar(&all, "all", false, "include cycles that only pass through dependencies")
+	flags.StringVar(&format, "format", "text", "output format, one of text or json")
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	decls, err := load(flags.Args(), build.options(nil))
+	if err != nil {
+		return err
+	}
+
+	cycles := []jsonCycle{}
+
+	for _, component := range graph.Cycles(decls) {
+		if !all && !initial(decls, component) {
+			continue
+		}
+
+		cycles = append(cycles, jsonCycle{
+			Functions: component,
+			Cycle:     policy.Cycle(decls, component),
+		})
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(cycles)
+	}
+
+	fmt.Printf("Found %d cycles\n", len(cycles))
+	for index, cycle := range cycles {
+		fmt.Printf("Cycle %d/%d: %s\n", index+1, len(cycles), strings.Join(cycle.Functions, ", "))
+		fmt.Println(cycle.Cycle)
+	}
+
+	return nil
+}
+
+// initial returns true if any of the named functions are declared in the
+// packages named on the command line.
+func initial(decls map[string]graph.FuncDecl, names []string) bool {
+	for _, name := range names {
+		if decls[name].Initial {
+			return true
+		}
+	}
+	return false
+}
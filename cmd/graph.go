@@ -0,0 +1,70 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+// graphFormats maps the name of each supported graph export format to its
+// writer.
+var graphFormats = map[string]func(io.Writer, map[string]graph.FuncDecl) error{
+	"dot":     graph.WriteDOT,
+	"graphml": graph.WriteGraphML,
+	"json":    graph.WriteJSON,
+}
+
+// graphCmd writes the call graph of the given packages to stdout, optionally
+// restricted to the neighbourhood of the functions matched by --focus.
+func graphCmd(args []string) error {
+	var (
+		format string
+		focus  string
+		depth  int
+		build  buildFlags
+	)
+
+	flags := flag.NewFlagSet("callcheck graph", flag.ContinueOnError)
+	flags.StringVar(&format, "format", "dot", "output format, one of dot, graphml, or json")
+	flags.StringVar(&focus, "focus", "", "comma separated function name globs or package patterns to focus on")
+	flags.IntVar(&depth, "depth", 1, "maximum number of calls away from a focused function to include, or -1 for no limit")
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	write, found := graphFormats[format]
+	if !found {
+		return fmt.Errorf("unknown graph format %q", format)
+	}
+
+	decls, err := load(flags.Args(), build.options(nil))
+	if err != nil {
+		return err
+	}
+
+	if focus != "" {
+		decls = graph.Focus(decls, patterns(focus), depth)
+	}
+
+	return write(os.Stdout, decls)
+}
+
+// patterns parses a comma separated list of function name globs or package
+// patterns.
+func patterns(list string) []graph.Pattern {
+	var patterns []graph.Pattern
+	for _, pattern := range strings.Split(list, ",") {
+		patterns = append(patterns, graph.NewPattern(strings.TrimSpace(pattern)))
+	}
+	return patterns
+}
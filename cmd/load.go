@@ -0,0 +1,177 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"os"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/loader"
+
+	"github.com/joshdk/callcheck/cache"
+	"github.com/joshdk/callcheck/config"
+	"github.com/joshdk/callcheck/graph"
+)
+
+// cacheEnv names the environment variable that selects the directory used to
+// cache package call graphs. Setting it to "off" disables caching.
+const cacheEnv = "CALLCHECK_CACHE"
+
+// loadOptions controls how packages are loaded.
+type loadOptions struct {
+	// targets lists the build configurations to load packages under. If
+	// empty, the default build configuration is used.
+	targets []config.Target
+
+	// tests includes the _test.go files of the named packages.
+	tests bool
+}
+
+// load parses and type checks the packages named by args once for every given
+// build configuration, and builds a call graph from the resulting programs.
+// When more than one configuration is given, the call graphs are merged, and
+// every function and call is labelled with the configurations it occurs in.
+func load(args []string, opts loadOptions) (map[string]graph.FuncDecl, error) {
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+
+	targets := opts.targets
+	if len(targets) == 0 {
+		targets = []config.Target{{}}
+	}
+
+	paths := gotool.ImportPaths(args)
+
+	if len(targets) == 1 {
+		return loadTarget(paths, buildContext(targets[0]), opts.tests)
+	}
+
+	merged := make(map[string]graph.FuncDecl)
+
+	for _, target := range targets {
+		ctxt := buildContext(target)
+
+		decls, err := loadTarget(paths, ctxt, opts.tests)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", target, err)
+		}
+
+		// Label with the fully resolved configuration, rather than the
+		// possibly partial target.
+		label := config.Target{GOOS: ctxt.GOOS, GOARCH: ctxt.GOARCH, Tags: target.Tags}.String()
+
+		graph.Merge(merged, decls, label)
+	}
+
+	return merged, nil
+}
+
+// loadTarget loads the packages named by paths using the given build context.
+// The call graphs of packages found in the cache are reused, and their
+// function bodies are not type checked.
+func loadTarget(paths []string, ctxt *build.Context, tests bool) (map[string]graph.FuncDecl, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := loader.Config{
+		Build: ctxt,
+		Cwd:   cwd,
+
+		// Doc comments are needed to find deprecated functions.
+		ParserMode: parser.ParseComments,
+	}
+
+	var (
+		store  = openCache()
+		keys   map[string]string
+		cached = make(map[string]map[string]graph.FuncDecl)
+	)
+
+	if store != nil {
+		keys = cache.Keys(cfg.Build, cwd, paths, tests)
+
+		for path, key := range keys {
+			if key == "" {
+				continue
+			}
+
+			if decls, found := store.Get(key); found {
+				cached[path] = decls
+			}
+		}
+
+		cfg.TypeCheckFuncBodies = func(path string) bool {
+			_, found := cached[path]
+			return !found
+		}
+	}
+
+	if _, err := cfg.FromArgs(paths, tests); err != nil {
+		return nil, err
+	}
+
+	program, err := cfg.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	decls := make(map[string]graph.FuncDecl)
+
+	for _, pkgInfo := range program.AllPackages {
+		path := pkgInfo.Pkg.Path()
+
+		pkgDecls, found := cached[path]
+		if !found {
+			pkgDecls = graph.Package(program.Fset, &pkgInfo.Info, pkgInfo.Files)
+
+			if key := keys[path]; store != nil && key != "" {
+				if err := store.Put(key, pkgDecls); err != nil {
+					fmt.Fprintf(os.Stderr, "callcheck: caching %s: %v\n", path, err)
+				}
+			}
+		}
+
+		for name, decl := range pkgDecls {
+			decls[name] = decl
+		}
+	}
+
+	// Cached call graphs are shared between runs, so only mark the packages
+	// named on the command line once they have been loaded.
+	graph.MarkInitial(decls, graph.InitialPackages(program))
+
+	return decls, nil
+}
+
+// openCache opens the cache directory named by the environment, falling back
+// to the default cache directory. A nil cache is returned if caching has been
+// disabled, or if the cache directory is unusable.
+func openCache() *cache.Cache {
+	dir := os.Getenv(cacheEnv)
+
+	switch dir {
+	case "off":
+		return nil
+	case "":
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			return nil
+		}
+	}
+
+	store, err := cache.Open(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "callcheck: disabling cache: %v\n", err)
+		return nil
+	}
+
+	return store
+}
@@ -0,0 +1,231 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+// metricColumn is a single metric, measured both for functions and for
+// packages.
+type metricColumn struct {
+	name     string
+	function func(graph.Metrics) int
+	pkg      func(graph.PackageMetrics) int
+}
+
+// metricColumns lists every metric that can be sorted on, or limited by a
+// threshold.
+var metricColumns = []metricColumn{
+	{
+		name:     "fan_in",
+		function: func(m graph.Metrics) int { return m.FanIn },
+		pkg:      func(m graph.PackageMetrics) int { return m.FanIn },
+	},
+	{
+		name:     "fan_out",
+		function: func(m graph.Metrics) int { return m.FanOut },
+		pkg:      func(m graph.PackageMetrics) int { return m.FanOut },
+	},
+	{
+		name:     "transitive_fan_in",
+		function: func(m graph.Metrics) int { return m.TransitiveFanIn },
+		pkg:      func(m graph.PackageMetrics) int { return m.MaxTransitiveFanIn },
+	},
+	{
+		name:     "transitive_fan_out",
+		function: func(m graph.Metrics) int { return m.TransitiveFanOut },
+		pkg:      func(m graph.PackageMetrics) int { return m.MaxTransitiveFanOut },
+	},
+	{
+		name:     "depth",
+		function: func(m graph.Metrics) int { return m.Depth },
+		pkg:      func(m graph.PackageMetrics) int { return m.MaxDepth },
+	},
+}
+
+// metricsCmd prints the fan in, fan out, and depth of every function declared
+// in the given packages, or of the packages themselves. If any function, or
+// package when aggregating by package, exceeds one of the given thresholds,
+// the run fails.
+func metricsCmd(args []string) error {
+	var (
+		format     string
+		packages   bool
+		sortBy     string
+		top        int
+		thresholds = make(map[string]*int)
+		build      buildFlags
+	)
+
+	flags := flag.NewFlagSet("callcheck metrics", flag.ContinueOnError)
+	flags.StringVar(&format, "format", "csv", "output format, one of csv or json")
+	flags.BoolVar(&packages, "packages", false, "print metrics aggregated by package instead of by function")
+	flags.StringVar(&sortBy, "sort", "name", "metric to sort by in descending order, or name")
+	flags.IntVar(&top, "top", 0, "maximum number of rows to print, or 0 for no limit")
+	for _, column := range metricColumns {
+		thresholds[column.name] = flags.Int("max-"+strings.Replace(column.name, "_", "-", -1), 0, fmt.Sprintf("fail if any function, or package with --packages, has a %s greater than this, or 0 for no limit", strings.Replace(column.name, "_", " ", -1)))
+	}
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	column, found := findColumn(sortBy)
+	if !found && sortBy != "name" {
+		return fmt.Errorf("unknown metric %q", sortBy)
+	}
+
+	decls, err := load(flags.Args(), build.options(nil))
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name, decl := range decls {
+		if decl.Initial {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	metrics := graph.Measure(decls, names)
+
+	var exceeded bool
+
+	if packages {
+		aggregates := graph.Aggregate(decls, metrics)
+		for _, aggregate := range aggregates {
+			for _, column := range metricColumns {
+				if limit := *thresholds[column.name]; limit > 0 && column.pkg(aggregate) > limit {
+					fmt.Fprintf(os.Stderr, "%s has a %s of %d, exceeding the limit of %d\n", aggregate.Package, strings.Replace(column.name, "_", " ", -1), column.pkg(aggregate), limit)
+					exceeded = true
+				}
+			}
+		}
+
+		if found {
+			sort.SliceStable(aggregates, func(i, j int) bool {
+				return column.pkg(aggregates[i]) > column.pkg(aggregates[j])
+			})
+		}
+		if top > 0 && len(aggregates) > top {
+			aggregates = aggregates[:top]
+		}
+		err = writeMetrics(format, aggregates, packageRows(aggregates))
+	} else {
+		for _, metric := range metrics {
+			for _, column := range metricColumns {
+				if limit := *thresholds[column.name]; limit > 0 && column.function(metric) > limit {
+					fmt.Fprintf(os.Stderr, "%s: %s has a %s of %d, exceeding the limit of %d\n", metric.Position, metric.Name, strings.Replace(column.name, "_", " ", -1), column.function(metric), limit)
+					exceeded = true
+				}
+			}
+		}
+
+		if found {
+			sort.SliceStable(metrics, func(i, j int) bool {
+				return column.function(metrics[i]) > column.function(metrics[j])
+			})
+		}
+		if top > 0 && len(metrics) > top {
+			metrics = metrics[:top]
+		}
+		err = writeMetrics(format, metrics, functionRows(metrics))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if exceeded {
+		return errors.New("metric thresholds exceeded")
+	}
+
+	return nil
+}
+
+// findColumn returns the metric with the given name.
+func findColumn(name string) (metricColumn, bool) {
+	for _, column := range metricColumns {
+		if column.name == name {
+			return column, true
+		}
+	}
+	return metricColumn{}, false
+}
+
+// writeMetrics writes either the given value as json, or the given rows as
+// csv.
+func writeMetrics(format string, value interface{}, rows [][]string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	writer := csv.NewWriter(os.Stdout)
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// functionRows formats the given function metrics as csv rows, including a
+// header.
+func functionRows(metrics []graph.Metrics) [][]string {
+	rows := [][]string{{"name", "package", "position", "fan_in", "fan_out", "transitive_fan_in", "transitive_fan_out", "depth"}}
+
+	for _, metric := range metrics {
+		rows = append(rows, []string{
+			metric.Name,
+			metric.Package,
+			metric.Position,
+			strconv.Itoa(metric.FanIn),
+			strconv.Itoa(metric.FanOut),
+			strconv.Itoa(metric.TransitiveFanIn),
+			strconv.Itoa(metric.TransitiveFanOut),
+			strconv.Itoa(metric.Depth),
+		})
+	}
+
+	return rows
+}
+
+// packageRows formats the given package metrics as csv rows, including a
+// header.
+func packageRows(metrics []graph.PackageMetrics) [][]string {
+	rows := [][]string{{"package", "functions", "fan_in", "fan_out", "max_transitive_fan_in", "max_transitive_fan_out", "max_depth"}}
+
+	for _, metric := range metrics {
+		rows = append(rows, []string{
+			metric.Package,
+			strconv.Itoa(metric.Functions),
+			strconv.Itoa(metric.FanIn),
+			strconv.Itoa(metric.FanOut),
+			strconv.Itoa(metric.MaxTransitiveFanIn),
+			strconv.Itoa(metric.MaxTransitiveFanOut),
+			strconv.Itoa(metric.MaxDepth),
+		})
+	}
+
+	return rows
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/policy"
+)
+
+// panicsCmd prints every exported function declared in the given packages
+// that can reach a call to panic, or a call that exits the program, along with
+// a witness chain of calls. Panics that are recovered along the way are not
+// reported.
+func panicsCmd(args []string) error {
+	var (
+		sinks  string
+		format string
+		build  buildFlags
+	)
+
+	flags := flag.NewFlagSet("callcheck panics", flag.ContinueOnError)
+	flags.StringVar(&sinks, "sinks", strings.Join(policy.DefaultSinks, ","), "comma separated function name globs that panic or exit")
+	flags.StringVar(&format, "format", "text", "output format, one of text or json")
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	decls, err := load(flags.Args(), build.options(nil))
+	if err != nil {
+		return err
+	}
+
+	var roots []string
+	for name, decl := range decls {
+		if decl.Initial && !decl.Test && graph.IsExported(name) {
+			roots = append(roots, name)
+		}
+	}
+
+	sort.Strings(roots)
+
+	var names []string
+	for _, sink := range strings.Split(sinks, ",") {
+		names = append(names, strings.TrimSpace(sink))
+	}
+
+	witnesses := policy.Panics(decls, roots, names)
+
+	if format == "json" {
+		if witnesses == nil {
+			witnesses = []policy.Decl{}
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(witnesses)
+	}
+
+	fmt.Printf("Found %d exported functions that can panic or exit\n", len(witnesses))
+	for index, witness := range witnesses {
+		fmt.Printf("Function %d/%d: %s\n", index+1, len(witnesses), witness.Name)
+		fmt.Println(witness)
+	}
+
+	return nil
+}
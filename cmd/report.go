@@ -5,16 +5,44 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/joshdk/callcheck/config"
+	"github.com/joshdk/callcheck/gitdiff"
 	"github.com/joshdk/callcheck/graph"
 	"github.com/joshdk/callcheck/policy"
 )
 
-func violations(callGraph map[string]graph.FuncDecl, cfg *config.Config) bool {
+// result holds every violation found for a single forbidden policy.
+type result struct {
+	policy     policy.Policy
+	violations []policy.Decl
 
-	var dirty bool
+	// changed describes the modified functions and call sites for each
+	// violation, when checking against a diff.
+	changed [][]string
+
+	// configs lists the build configurations in which each violation
+	// occurs, when checking multiple configurations.
+	configs [][]string
+}
+
+// reportFormats maps the name of each supported output format to its printer.
+var reportFormats = map[string]func([]result) error{
+	"text": printText,
+	"dot":  printDOT,
+	"json": printJSON,
+}
+
+// violations finds every violation of every forbidden policy, returning only
+// the results for policies that were violated. If changes is non-nil, only
+// violations that include a modified function or call site are considered.
+func violations(callGraph map[string]graph.FuncDecl, cfg *config.Config, changes gitdiff.Changes) []result {
+
+	var results []result
 
 	// Examine each policy
 	for _, forbiddenPolicy := range cfg.Forbidden {
@@ -22,25 +50,131 @@ func violations(callGraph map[string]graph.FuncDecl, cfg *config.Config) bool {
 		// Find all violations for this policy
 		violations := policy.MatchingPaths(callGraph, forbiddenPolicy)
 
-		if len(violations) == 0 {
+		// Discard violations that were not touched by the diff.
+		var touched [][]string
+		if changes != nil {
+			violations, touched = policy.FilterChanged(violations, callGraph, changes)
+		}
+
+		// Discard violations that combine calls from different build
+		// configurations, as they cannot occur in practice.
+		var (
+			kept    []policy.Decl
+			keptSet [][]string
+			configs [][]string
+		)
+
+		for index, violation := range violations {
+			labels := configurations(violation, callGraph)
+			if labels != nil && len(labels) == 0 {
+				continue
+			}
+
+			kept = append(kept, violation)
+			configs = append(configs, labels)
+			if touched != nil {
+				keptSet = append(keptSet, touched[index])
+			}
+		}
+
+		if len(kept) == 0 {
 			continue
 		}
 
-		dirty = true
+		results = append(results, result{forbiddenPolicy, kept, keptSet, configs})
+	}
 
-		fmt.Printf("Found %d violations for %s\n", len(violations), forbiddenPolicy.Name)
+	return results
+}
 
-		for index, violation := range violations {
+func printText(results []result) error {
+	for _, result := range results {
+		fmt.Printf("Found %d violations for %s\n", len(result.violations), result.policy.Name)
+		if result.policy.Description != "" {
+			fmt.Printf("Description: %s\n", result.policy.Description)
+		}
+
+		for index, violation := range result.violations {
 			if index == 10 {
-				fmt.Printf("Violation %d...%d/%d omitted\n", index+1, len(violations), len(violations))
+				fmt.Printf("Violation %d...%d/%d omitted\n", index+1, len(result.violations), len(result.violations))
 				break
 			}
 
-			fmt.Printf("Violation %d/%d\n", index+1, len(violations))
+			fmt.Printf("Violation %d/%d\n", index+1, len(result.violations))
+
+			explained := policy.Violation{Policy: result.policy, Decl: violation}
+			if message := explained.Message(); message != "" {
+				fmt.Printf("Message: %s\n", message)
+			}
+			if remediation := explained.Remediation(); remediation != "" {
+				fmt.Printf("Remediation: %s\n", remediation)
+			}
+			if url := explained.URL(); url != "" {
+				fmt.Printf("URL: %s\n", url)
+			}
+			if result.changed != nil {
+				fmt.Printf("Changed: %s\n", strings.Join(result.changed[index], ", "))
+			}
+			if configs := result.configs[index]; configs != nil {
+				fmt.Printf("Configurations: %s\n", strings.Join(configs, ", "))
+			}
 			fmt.Println(violation)
 		}
+	}
+
+	return nil
+}
+
+func printDOT(results []result) error {
+	var all []policy.Violation
+
+	for _, result := range results {
+		for _, violation := range result.violations {
+			all = append(all, policy.Violation{Policy: result.policy, Decl: violation})
+		}
+	}
 
+	return policy.WriteDOT(os.Stdout, all)
+}
+
+// jsonViolation is a single violation, as written by printJSON.
+type jsonViolation struct {
+	Policy         string      `json:"policy"`
+	Description    string      `json:"description,omitempty"`
+	Message        string      `json:"message,omitempty"`
+	Remediation    string      `json:"remediation,omitempty"`
+	URL            string      `json:"url,omitempty"`
+	Changed        []string    `json:"changed,omitempty"`
+	Configurations []string    `json:"configurations,omitempty"`
+	Violation      policy.Decl `json:"violation"`
+}
+
+func printJSON(results []result) error {
+	all := []jsonViolation{}
+
+	for _, result := range results {
+		for index, violation := range result.violations {
+			explained := policy.Violation{Policy: result.policy, Decl: violation}
+
+			entry := jsonViolation{
+				Policy:         result.policy.Name,
+				Description:    result.policy.Description,
+				Message:        explained.Message(),
+				Remediation:    explained.Remediation(),
+				URL:            explained.URL(),
+				Configurations: result.configs[index],
+				Violation:      violation,
+			}
+
+			if result.changed != nil {
+				entry.Changed = result.changed[index]
+			}
+
+			all = append(all, entry)
+		}
 	}
 
-	return dirty
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(all)
 }
@@ -0,0 +1,59 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/joshdk/callcheck/config"
+	"github.com/joshdk/callcheck/policytest"
+)
+
+// failures records every failure reported by policytest.Run.
+type failures []string
+
+func (f *failures) Errorf(format string, args ...interface{}) {
+	*f = append(*f, fmt.Sprintf(format, args...))
+}
+
+// testCmd checks the policies in the config file against the packages found
+// in a testdata directory, using want comments as the expected violations.
+// The first argument names the testdata directory, and defaults to
+// "testdata". Any remaining arguments select which packages to check.
+func testCmd(args []string) error {
+	dir := "testdata"
+	if len(args) > 0 {
+		dir, args = args[0], args[1:]
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if problems := cfg.Validate(); len(problems) != 0 {
+		return problems
+	}
+
+	var results failures
+
+	policytest.Run(&results, dir, cfg.Forbidden, args...)
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	for _, result := range results {
+		fmt.Println(result)
+	}
+
+	return fmt.Errorf("%d policy test failures", len(results))
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+// jsonFunction is a single unreachable function, as written by
+// unreachableCmd.
+type jsonFunction struct {
+	Name     string `json:"name"`
+	Package  string `json:"package"`
+	Position string `json:"position"`
+}
+
+// unreachableCmd prints every function declared in the given packages that
+// cannot be reached by any chain of calls from an entrypoint, such as a main,
+// init, test, or exported function, or from a function matched by --roots.
+func unreachableCmd(args []string) error {
+	var (
+		roots       string
+		allow       string
+		entrypoints bool
+		format      string
+		build       buildFlags
+	)
+
+	flags := flag.NewFlagSet("callcheck unreachable", flag.ContinueOnError)
+	flags.StringVar(&roots, "roots", "", "comma separated function name globs or package patterns to treat as reachable roots")
+	flags.StringVar(&allow, "allow", "", "comma separated function name globs or package patterns to never report, such as those called by reflection")
+	flags.BoolVar(&entrypoints, "entrypoints", true, "treat main, init, test, and exported functions as reachable roots")
+	flags.StringVar(&format, "format", "text", "output format, one of text or json")
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+
+	decls, err := load(flags.Args(), build.options(nil))
+	if err != nil {
+		return err
+	}
+
+	reachable := make(map[string]struct{})
+
+	if entrypoints {
+		reachable = graph.Entrypoints(decls)
+	}
+
+	if roots != "" {
+		rootPatterns := patterns(roots)
+		for name, decl := range decls {
+			if matchesAny(rootPatterns, decl) {
+				reachable[name] = struct{}{}
+			}
+		}
+	}
+
+	var allowed []graph.Pattern
+	if allow != "" {
+		allowed = patterns(allow)
+	}
+
+	functions := []jsonFunction{}
+
+	for _, name := range graph.Unreachable(decls, reachable) {
+		decl := decls[name]
+
+		if matchesAny(allowed, decl) {
+			continue
+		}
+
+		functions = append(functions, jsonFunction{
+			Name:     name,
+			Package:  decl.Package,
+			Position: decl.Position,
+		})
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(functions)
+	}
+
+	width := 0
+	for _, function := range functions {
+		if len(function.Position) > width {
+			width = len(function.Position)
+		}
+	}
+
+	fmt.Printf("Found %d unreachable functions\n", len(functions))
+	for _, function := range functions {
+		fmt.Printf("%s%s → %s\n", function.Position, strings.Repeat(" ", width-len(function.Position)), function.Name)
+	}
+
+	return nil
+}
+
+// matchesAny reports whether any of the given patterns match the given
+// function.
+func matchesAny(patterns []graph.Pattern, decl graph.FuncDecl) bool {
+	for _, pattern := range patterns {
+		if pattern.Match(decl.Name, decl.Package) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/policy"
+)
+
+// whyCmd prints the paths by which one function reaches another. Either name
+// may be a glob, in which case every matching pair of functions is queried.
+func whyCmd(args []string) error {
+	var (
+		shortest bool
+		build    buildFlags
+	)
+
+	flags := flag.NewFlagSet("callcheck why", flag.ContinueOnError)
+	flags.BoolVar(&shortest, "shortest", false, "only print the shortest paths")
+	build.register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() < 2 {
+		return errors.New("usage: callcheck why [--shortest] <from> <to> [packages]")
+	}
+
+	from, to := flags.Arg(0), flags.Arg(1)
+
+	decls, err := load(flags.Args()[2:], build.options(nil))
+	if err != nil {
+		return err
+	}
+
+	var paths []policy.Decl
+
+	for _, start := range graph.Expand(from, decls, false) {
+		for _, end := range graph.Expand(to, decls, true) {
+			paths = append(paths, policy.Paths(decls, start, end)...)
+		}
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths found from %s to %s", from, to)
+	}
+
+	if shortest {
+		paths = policy.ShortestPaths(paths)
+	}
+
+	for index, path := range paths {
+		fmt.Printf("Path %d/%d\n", index+1, len(paths))
+		fmt.Println(path)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/joshdk/callcheck/policytest"
+)
+
+func TestBuiltinBundles(t *testing.T) {
+	// Every bundle is checked against the package of the same name, which
+	// imports a package that breaks every policy of every bundle.
+	for _, name := range Builtins() {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := LoadBuiltin(name)
+			require.NoError(t, err)
+
+			policytest.Run(t, policytest.TestData(), cfg.Forbidden, name)
+		})
+	}
+}
@@ -5,9 +5,73 @@
 package config
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/joshdk/callcheck/policy"
 )
 
 type Config struct {
+	// Extends lists config files whose policies are inherited, in order.
+	// Relative paths are resolved against the directory of this file, and
+	// names starting with "builtin:" refer to one of the built-in bundles.
+	// Policies with the same name override those inherited before them.
+	Extends []string `yaml:"extends"`
+
+	// Disable lists the names of inherited policies to remove.
+	Disable []string `yaml:"disable"`
+
 	Forbidden []policy.Policy `yaml:"forbid"`
+
+	// Matrix lists the build configurations that the program is loaded and
+	// checked under. If empty, only the default configuration is used.
+	Matrix []Target `yaml:"matrix"`
+
+	// filename is the name of the file that this config was loaded from.
+	filename string
+
+	// policies records the position of each forbidden policy, indexed in
+	// parallel with Forbidden.
+	policies []Position
+
+	// nodes records the position of every rule node.
+	nodes map[*policy.Node]Position
+
+	// extends and disabled record the position of each entry in Extends and
+	// Disable respectively.
+	extends  []Position
+	disabled []Position
+}
+
+// PolicyPosition returns the position where the forbidden policy at the given
+// index was declared.
+func (cfg *Config) PolicyPosition(index int) Position {
+	if index < len(cfg.policies) {
+		return cfg.policies[index]
+	}
+	return Position{Filename: cfg.filename}
+}
+
+// NodePosition returns the position where the given rule node was declared.
+func (cfg *Config) NodePosition(node *policy.Node) Position {
+	if pos, found := cfg.nodes[node]; found {
+		return pos
+	}
+	return Position{Filename: cfg.filename}
+}
+
+// Target is a single build configuration. Empty fields take their value from
+// the default build context.
+type Target struct {
+	GOOS   string   `yaml:"goos"`
+	GOARCH string   `yaml:"goarch"`
+	Tags   []string `yaml:"tags"`
+}
+
+func (target Target) String() string {
+	name := fmt.Sprintf("%s/%s", target.GOOS, target.GOARCH)
+	if len(target.Tags) != 0 {
+		name += fmt.Sprintf(" [%s]", strings.Join(target.Tags, ","))
+	}
+	return name
 }
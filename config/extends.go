@@ -0,0 +1,205 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package config
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joshdk/callcheck/policy"
+)
+
+// builtinPrefix starts every entry of extends that names a built-in bundle,
+// rather than a file.
+const builtinPrefix = "builtin:"
+
+//go:embed bundles/*.yml
+var bundles embed.FS
+
+// Builtins returns the names of every built-in bundle, sorted.
+func Builtins() []string {
+	entries, _ := bundles.ReadDir("bundles")
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yml"))
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// LoadBuiltin loads the named built-in bundle.
+func LoadBuiltin(name string) (*Config, error) {
+	return loadBuiltin(name, nil)
+}
+
+// loadBuiltin loads the named built-in bundle, which is extended by every
+// config in chain.
+func loadBuiltin(name string, chain []string) (*Config, error) {
+	body, err := bundles.ReadFile(path.Join("bundles", name+".yml"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown built-in bundle %q, expected one of %s", name, strings.Join(Builtins(), ", "))
+	}
+
+	cfg, err := parse(builtinPrefix+name, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg.extend(chain)
+}
+
+// Builtin reports whether the given position lies within a built-in bundle.
+func (pos Position) Builtin() bool {
+	return strings.HasPrefix(pos.Filename, builtinPrefix)
+}
+
+// extend loads every config that this config extends, and merges them into a
+// single config. Inherited policies are overridden by later policies with the
+// same name, keeping their original place in the order, and are then removed
+// if they are disabled. The build matrix of this config replaces any that was
+// inherited.
+func (cfg *Config) extend(chain []string) (*Config, error) {
+	if len(cfg.Extends) == 0 && len(cfg.Disable) == 0 {
+		return cfg, nil
+	}
+
+	chain = append(chain, cfg.filename)
+
+	merged := &Config{
+		Extends:  cfg.Extends,
+		Disable:  cfg.Disable,
+		filename: cfg.filename,
+		nodes:    make(map[*policy.Node]Position),
+		extends:  cfg.extends,
+		disabled: cfg.disabled,
+	}
+
+	var problems Problems
+
+	for index, name := range cfg.Extends {
+		pos := cfg.entryPosition(cfg.extends, index)
+
+		base, err := cfg.loadBase(name, chain)
+		if problem, ok := err.(Problems); ok {
+			problems = append(problems, problem...)
+			continue
+		} else if err != nil {
+			problems = append(problems, Problem{Position: pos, Message: err.Error()})
+			continue
+		}
+
+		merged.inherit(base)
+	}
+
+	merged.inherit(cfg)
+
+	for index, name := range cfg.Disable {
+		if !merged.remove(name) {
+			problems = append(problems, Problem{Position: cfg.entryPosition(cfg.disabled, index), Message: fmt.Sprintf("cannot disable unknown policy %q", name)})
+		}
+	}
+
+	if len(problems) != 0 {
+		return nil, problems
+	}
+
+	return merged, nil
+}
+
+// loadBase loads the named config that this config extends, unless doing so
+// would create a cycle.
+func (cfg *Config) loadBase(name string, chain []string) (*Config, error) {
+	filename := name
+	if !strings.HasPrefix(name, builtinPrefix) && !filepath.IsAbs(name) {
+		filename = filepath.Join(filepath.Dir(cfg.filename), name)
+	}
+
+	for _, previous := range chain {
+		if filepath.Clean(previous) == filepath.Clean(filename) {
+			return nil, fmt.Errorf("cannot extend %s, as it already extends %s", name, cfg.filename)
+		}
+	}
+
+	if strings.HasPrefix(name, builtinPrefix) {
+		return loadBuiltin(strings.TrimPrefix(name, builtinPrefix), chain)
+	}
+
+	return loadFile(filename, chain)
+}
+
+// inherit adds every policy of base to this config, replacing any existing
+// policy with the same name. Each existing policy is only replaced once, so
+// that duplicates within base are still reported by Validate.
+func (cfg *Config) inherit(base *Config) {
+	overrides := make(map[string]struct{}, len(cfg.Forbidden))
+	for _, forbidden := range cfg.Forbidden {
+		overrides[forbidden.Name] = struct{}{}
+	}
+
+	for index, forbidden := range base.Forbidden {
+		existing := -1
+		if _, found := overrides[forbidden.Name]; found {
+			existing = cfg.find(forbidden.Name)
+		}
+
+		if existing < 0 {
+			cfg.Forbidden = append(cfg.Forbidden, forbidden)
+			cfg.policies = append(cfg.policies, base.PolicyPosition(index))
+		} else {
+			cfg.Forbidden[existing] = forbidden
+			cfg.policies[existing] = base.PolicyPosition(index)
+			delete(overrides, forbidden.Name)
+		}
+	}
+
+	for node, pos := range base.nodes {
+		cfg.nodes[node] = pos
+	}
+
+	if len(base.Matrix) != 0 {
+		cfg.Matrix = base.Matrix
+	}
+}
+
+// find returns the index of the policy with the given name, or -1 if there is
+// none.
+func (cfg *Config) find(name string) int {
+	for index, forbidden := range cfg.Forbidden {
+		if forbidden.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// remove deletes the policy with the given name, and reports whether it was
+// found.
+func (cfg *Config) remove(name string) bool {
+	index := cfg.find(name)
+	if index < 0 {
+		return false
+	}
+
+	cfg.Forbidden = append(cfg.Forbidden[:index], cfg.Forbidden[index+1:]...)
+	cfg.policies = append(cfg.policies[:index], cfg.policies[index+1:]...)
+
+	return true
+}
+
+// entryPosition returns the position at the given index of the given list of
+// entry positions.
+func (cfg *Config) entryPosition(positions []Position, index int) Position {
+	if index < len(positions) {
+		return positions[index]
+	}
+	return Position{Filename: cfg.filename}
+}
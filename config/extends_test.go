@@ -0,0 +1,185 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtends(t *testing.T) {
+
+	tests := []struct {
+		title    string
+		files    map[string]string
+		names    []string
+		matrix   []Target
+		problems []string
+	}{
+		{
+			title: "override and disable",
+			files: map[string]string{
+				"base.yml": `
+					matrix:
+					  - goos: linux
+					forbid:
+					  - name: a
+					    forbid_recursion: true
+					  - name: b
+					    forbid_recursion: true
+					  - name: c
+					    forbid_recursion: true
+				`,
+				"callcheck.yml": `
+					extends: [base.yml]
+					disable: [b]
+					forbid:
+					  - name: d
+					    forbid_recursion: true
+					  - name: a
+					    forbid_deprecated: true
+				`,
+			},
+			names:  []string{"a", "c", "d"},
+			matrix: []Target{{GOOS: "linux"}},
+		},
+		{
+			title: "later bases override earlier bases",
+			files: map[string]string{
+				"common/base.yml": `
+					forbid:
+					  - name: a
+					    forbid_recursion: true
+				`,
+				"team/base.yml": `
+					extends: [../common/base.yml]
+					matrix:
+					  - goos: darwin
+					forbid:
+					  - name: b
+					    forbid_recursion: true
+				`,
+				"callcheck.yml": `
+					extends: [team/base.yml, common/base.yml]
+					matrix:
+					  - goos: windows
+				`,
+			},
+			names:  []string{"a", "b"},
+			matrix: []Target{{GOOS: "windows"}},
+		},
+		{
+			title: "builtin bundles",
+			files: map[string]string{
+				"callcheck.yml": `
+					extends: ["builtin:security"]
+					disable: [security-insecure-random]
+				`,
+			},
+			names: []string{"security-weak-hash", "security-weak-cipher"},
+		},
+		{
+			title: "duplicates within a file",
+			files: map[string]string{
+				"base.yml": `
+					forbid:
+					  - name: a
+					    forbid_recursion: true
+				`,
+				"callcheck.yml": `
+					extends: [base.yml]
+					forbid:
+					  - name: a
+					    forbid_recursion: true
+					  - name: a
+					    forbid_recursion: true
+				`,
+			},
+			names: []string{"a", "a"},
+		},
+		{
+			title: "cycles and unknown names",
+			files: map[string]string{
+				"a.yml": `
+					extends: [callcheck.yml]
+				`,
+				"callcheck.yml": `
+					extends:
+					  - a.yml
+					  - "builtin:unknown"
+					  - missing.yml
+					disable: [b]
+				`,
+			},
+			problems: []string{
+				"a.yml:1:11: cannot extend callcheck.yml, as it already extends a.yml",
+				`callcheck.yml:3:5: unknown built-in bundle "unknown", expected one of determinism, library, security`,
+				"callcheck.yml:4:5: open missing.yml: no such file or directory",
+				`callcheck.yml:5:11: cannot disable unknown policy "b"`,
+			},
+		},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.title)
+
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			for filename, body := range test.files {
+				filename = filepath.Join(dir, filename)
+				require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+				require.NoError(t, os.WriteFile(filename, []byte(strings.TrimSpace(unindent(body))), 0644))
+			}
+
+			cfg, err := LoadFile(filepath.Join(dir, "callcheck.yml"))
+
+			if len(test.problems) != 0 {
+				require.IsType(t, Problems{}, err)
+
+				actual := make([]string, len(err.(Problems)))
+				for index, problem := range err.(Problems) {
+					actual[index] = strings.Replace(problem.String(), dir+string(filepath.Separator), "", -1)
+				}
+
+				assert.Equal(t, test.problems, actual)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var names []string
+			for _, forbidden := range cfg.Forbidden {
+				names = append(names, forbidden.Name)
+			}
+
+			assert.Equal(t, test.names, names)
+			assert.Equal(t, test.matrix, cfg.Matrix)
+		})
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	assert.Equal(t, []string{"determinism", "library", "security"}, Builtins())
+
+	for _, name := range Builtins() {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := loadBuiltin(name, nil)
+			require.NoError(t, err)
+			require.NotEmpty(t, cfg.Forbidden)
+			assert.Empty(t, cfg.Validate())
+
+			for index := range cfg.Forbidden {
+				assert.True(t, cfg.PolicyPosition(index).Builtin())
+			}
+		})
+	}
+}
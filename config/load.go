@@ -5,9 +5,14 @@
 package config
 
 import (
+	"fmt"
 	"io/ioutil"
+	"reflect"
+	"strings"
 
-	"gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/joshdk/callcheck/policy"
 )
 
 const (
@@ -18,17 +23,216 @@ func Load() (*Config, error) {
 	return LoadFile(DefaultFilename)
 }
 
+// LoadFile reads and decodes the named config file, along with every config
+// that it extends. Keys that do not correspond to a known config field are
+// treated as errors. If the file is malformed, the returned error will be of
+// type Problems.
 func LoadFile(filename string) (*Config, error) {
+	return loadFile(filename, nil)
+}
+
+// loadFile loads the named config file, which is extended by every config in
+// chain.
+func loadFile(filename string, chain []string) (*Config, error) {
 	body, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	var cfg Config
-
-	if err := yaml.Unmarshal(body, &cfg); err != nil {
+	cfg, err := parse(filename, body)
+	if err != nil {
 		return nil, err
 	}
 
+	return cfg.extend(chain)
+}
+
+func parse(filename string, body []byte) (*Config, error) {
+	cfg := Config{
+		filename: filename,
+		nodes:    make(map[*policy.Node]Position),
+	}
+
+	var document yaml.Node
+
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return nil, Problems{problemFromMessage(filename, err.Error())}
+	}
+
+	// The file was empty.
+	if len(document.Content) == 0 {
+		return &cfg, nil
+	}
+
+	root := document.Content[0]
+
+	// Reject any keys that would otherwise be silently ignored.
+	if problems := strict(filename, root, reflect.TypeOf(cfg)); len(problems) != 0 {
+		return nil, problems
+	}
+
+	if err := root.Decode(&cfg); err != nil {
+		if typeErr, ok := err.(*yaml.TypeError); ok {
+			problems := make(Problems, len(typeErr.Errors))
+			for index, message := range typeErr.Errors {
+				problems[index] = problemFromMessage(filename, message)
+			}
+			return nil, problems
+		}
+		return nil, Problems{problemFromMessage(filename, err.Error())}
+	}
+
+	cfg.record(root)
+
 	return &cfg, nil
 }
+
+// strict walks the given yaml node alongside the type it will be decoded
+// into, and reports every mapping key that has no corresponding field.
+func strict(filename string, node *yaml.Node, typ reflect.Type) Problems {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var problems Problems
+
+	switch {
+	case typ.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			problems = append(problems, strict(filename, item, typ.Elem())...)
+		}
+
+	case typ.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(typ)
+
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			key, value := node.Content[index], node.Content[index+1]
+
+			field, found := fields[key.Value]
+			if !found {
+				problems = append(problems, Problem{
+					Position: nodePosition(filename, key),
+					Message:  fmt.Sprintf("unknown field %q", key.Value),
+				})
+				continue
+			}
+
+			problems = append(problems, strict(filename, value, field.Type)...)
+		}
+	}
+
+	return problems
+}
+
+// yamlFields returns all fields of the given struct type, keyed by the name
+// that the field is decoded from.
+func yamlFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+
+		// Skip unexported fields.
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = field
+	}
+
+	return fields
+}
+
+// record walks the given yaml node alongside the decoded config, and saves the
+// position of every policy and rule node, along with every entry of extends
+// and disable.
+func (cfg *Config) record(root *yaml.Node) {
+	cfg.extends = recordEntries(cfg.filename, lookup(root, "extends"))
+	cfg.disabled = recordEntries(cfg.filename, lookup(root, "disable"))
+
+	forbid := lookup(root, "forbid")
+	if forbid == nil || forbid.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for index, item := range forbid.Content {
+		if index >= len(cfg.Forbidden) {
+			break
+		}
+
+		cfg.policies = append(cfg.policies, nodePosition(cfg.filename, item))
+		cfg.recordNode(lookup(item, "rule"), cfg.Forbidden[index].Rule)
+	}
+}
+
+func (cfg *Config) recordNode(node *yaml.Node, rule *policy.Node) {
+	if node == nil || rule == nil {
+		return
+	}
+
+	cfg.nodes[rule] = nodePosition(cfg.filename, node)
+
+	cfg.recordNodes(lookup(node, "calls"), rule.Calls)
+	cfg.recordNodes(lookup(node, "any_of"), rule.AnyOf)
+	cfg.recordNodes(lookup(node, "all_of"), rule.AllOf)
+	cfg.recordNodes(lookup(node, "none_of"), rule.NoneOf)
+}
+
+func (cfg *Config) recordNodes(node *yaml.Node, rules []*policy.Node) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for index, item := range node.Content {
+		if index >= len(rules) {
+			break
+		}
+
+		cfg.recordNode(item, rules[index])
+	}
+}
+
+// recordEntries returns the position of every item in a yaml sequence.
+func recordEntries(filename string, node *yaml.Node) []Position {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	positions := make([]Position, len(node.Content))
+	for index, item := range node.Content {
+		positions[index] = nodePosition(filename, item)
+	}
+
+	return positions
+}
+
+// lookup returns the value associated with the given key in a yaml mapping.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for index := 0; index+1 < len(node.Content); index += 2 {
+		if node.Content[index].Value == key {
+			return node.Content[index+1]
+		}
+	}
+
+	return nil
+}
+
+func nodePosition(filename string, node *yaml.Node) Position {
+	return Position{
+		Filename: filename,
+		Line:     node.Line,
+		Column:   node.Column,
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		title    string
+		body     string
+		problems []string
+	}{
+		{
+			title: "empty file",
+		},
+		{
+			title: "valid policy",
+			body: `
+				forbid:
+				  - name: no-exit
+				    rule:
+				      name: main.main
+				      calls:
+				        - name: os.Exit
+			`,
+		},
+		{
+			title: "unknown top level key",
+			body: `
+				forbids:
+				  - name: no-exit
+			`,
+			problems: []string{`test.yml:1:1: unknown field "forbids"`},
+		},
+		{
+			title: "unknown nested keys",
+			body: `
+				forbid:
+				  - name: no-exit
+				    rule:
+				      name: main.main
+				      call:
+				        - nam: os.Exit
+			`,
+			problems: []string{`test.yml:5:7: unknown field "call"`},
+		},
+		{
+			title: "mismatched type",
+			body: `
+				forbid:
+				  name: no-exit
+			`,
+			problems: []string{"test.yml:2: cannot unmarshal !!map into []policy.Policy"},
+		},
+		{
+			title: "empty and duplicate names",
+			body: `
+				forbid:
+				  - name: no-exit
+				    rule:
+				      name: main.main
+				  - name: no-exit
+				    rule:
+				      name: main.main
+				      calls:
+				        - name: ""
+				  - name: ""
+			`,
+			problems: []string{
+				`test.yml:5:5: duplicate policy name "no-exit" (first defined at test.yml:2:5)`,
+				"test.yml:9:11: rule name is empty",
+				"test.yml:10:5: policy name is empty",
+				`test.yml:10:5: policy "" has no rule`,
+			},
+		},
+		{
+			title: "combinators",
+			body: `
+				forbid:
+				  - name: env-exec
+				    rule:
+				      name: main.main
+				      calls:
+				        - any_of:
+				            - name: os.Getenv
+				            - name: os.LookupEnv
+				        - name: os/exec.Command
+				  - name: invalid
+				    rule:
+				      none_of:
+				        - name: os.Exit
+				      calls:
+				        - name: main.run
+				          any_of:
+				            - name: os.Exit
+				        - all_of:
+				            - name: os.Exit
+				          none_of:
+				            - name: ""
+			`,
+			problems: []string{
+				"test.yml:12:7: combinators are not allowed in the root of a rule",
+				"test.yml:15:11: rule node cannot have a name or calls alongside any_of, all_of, or none_of",
+				"test.yml:18:11: rule node can only have one of any_of, all_of, or none_of",
+				"test.yml:21:15: rule name is empty",
+			},
+		},
+		{
+			title: "call depths",
+			body: `
+				forbid:
+				  - name: direct-exec
+				    rule:
+				      name: main.handler
+				      direct: true
+				      calls:
+				        - name: db.Exec
+				          direct: true
+				        - name: db.Query
+				          max_depth: -1
+				        - max_depth: 2
+				          any_of:
+				            - name: os.Exit
+			`,
+			problems: []string{
+				"test.yml:4:7: direct and max_depth are only allowed on named calls",
+				"test.yml:9:11: rule max_depth -1 is negative",
+				"test.yml:11:11: direct and max_depth are only allowed on named calls",
+			},
+		},
+		{
+			title: "unknown order",
+			body: `
+				forbid:
+				  - name: unlock-lock
+				    rule:
+				      name: main.main
+				      order: dominates
+				      calls:
+				        - name: (*sync.Mutex).Unlock
+				        - name: (*sync.Mutex).Lock
+			`,
+			problems: []string{`test.yml:4:7: rule has unknown order "dominates"`},
+		},
+		{
+			title: "pairs",
+			body: `
+				forbid:
+				  - name: unpaired-lock
+				    pair:
+				      open: (*sync.Mutex).Lock
+				      close: (*sync.Mutex).Unlock
+				  - name: both
+				    pair:
+				      open: (*sync.Mutex).Lock
+				    rule:
+				      name: main.main
+			`,
+			problems: []string{
+				`test.yml:6:5: policy "both" can only have one of rule, pair, forbid_recursion, or forbid_deprecated`,
+				`test.yml:6:5: policy "both" pair must name both an open and a close function`,
+			},
+		},
+		{
+			title: "deprecated",
+			body: `
+				forbid:
+				  - name: no-deprecated
+				    forbid_deprecated: true
+				    transitive: true
+				  - name: transitive-rule
+				    transitive: true
+				    rule:
+				      name: main.main
+			`,
+			problems: []string{`test.yml:5:5: policy "transitive-rule" can only be transitive with forbid_deprecated`},
+		},
+		{
+			title: "invalid templates",
+			body: `
+				forbid:
+				  - name: no-exit
+				    message: "{{.Root}} must not call {{.Leaf}}"
+				    remediation: "{{.Caller}}"
+				    url: "{{.Root"
+				    rule:
+				      name: main.main
+			`,
+			problems: []string{
+				`test.yml:2:5: policy "no-exit" has an invalid remediation template: template: :1:2: executing "" at <.Caller>: can't evaluate field Caller in type policy.Fields`,
+				`test.yml:2:5: policy "no-exit" has an invalid url template: template: :1: unclosed action`,
+			},
+		},
+		{
+			title: "empty include pattern",
+			body: `
+				forbid:
+				  - name: api-panic
+				    include: [".../internal/api/...", ""]
+				    rule:
+				      name: panic
+			`,
+			problems: []string{`test.yml:2:5: policy "api-panic" has an empty include or exclude pattern`},
+		},
+		{
+			title: "empty intermediary pattern",
+			body: `
+				forbid:
+				  - name: api-panic
+				    intermediaries:
+				      exclude: [""]
+				    rule:
+				      name: panic
+			`,
+			problems: []string{`test.yml:2:5: policy "api-panic" has an empty include or exclude pattern`},
+		},
+		{
+			title: "wildcards below the root",
+			body: `
+				forbid:
+				  - name: no-exit
+				    rule:
+				      name: main.*
+				      calls:
+				        - name: os.*
+				        - name: (*sync.Mutex).Unlock
+			`,
+			problems: []string{`test.yml:6:11: rule name "os.*" contains wildcards, which are only allowed in the root of a rule`},
+		},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.title)
+
+		t.Run(name, func(t *testing.T) {
+			body := strings.TrimSpace(unindent(test.body))
+
+			cfg, err := parse("test.yml", []byte(body))
+
+			var problems Problems
+			if err != nil {
+				require.IsType(t, Problems{}, err)
+				problems = err.(Problems)
+			} else {
+				problems = cfg.Validate()
+			}
+
+			actual := make([]string, len(problems))
+			for index, problem := range problems {
+				actual[index] = problem.String()
+			}
+
+			if len(test.problems) == 0 {
+				assert.Empty(t, actual)
+			} else {
+				assert.Equal(t, test.problems, actual)
+			}
+		})
+	}
+}
+
+func unindent(body string) string {
+	return strings.Replace(body, "\t", "", -1)
+}
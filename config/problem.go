@@ -0,0 +1,96 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Position identifies a location inside of a config file.
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (pos Position) String() string {
+	switch {
+	case pos.Line == 0:
+		return pos.Filename
+	case pos.Column == 0:
+		return fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column)
+	}
+}
+
+// Severity classifies how serious a problem is.
+type Severity int
+
+const (
+	// Error problems prevent a config file from being used.
+	Error Severity = iota
+
+	// Warning problems indicate that a policy is likely to be ineffective,
+	// such as a rule that can never match.
+	Warning
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case Warning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Problem describes a single issue found while loading or validating a
+// config file.
+type Problem struct {
+	Position Position
+	Severity Severity
+	Message  string
+}
+
+func (problem Problem) String() string {
+	if problem.Severity == Warning {
+		return fmt.Sprintf("%s: %s: %s", problem.Position, problem.Severity, problem.Message)
+	}
+	return fmt.Sprintf("%s: %s", problem.Position, problem.Message)
+}
+
+// Problems is a list of every issue found in a config file. It satisfies the
+// error interface so that it can be returned from LoadFile.
+type Problems []Problem
+
+func (problems Problems) Error() string {
+	lines := make([]string, len(problems))
+	for index, problem := range problems {
+		lines[index] = problem.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+var lineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// problemFromMessage converts a yaml error message into a problem, extracting
+// the line number when one is present.
+func problemFromMessage(filename string, message string) Problem {
+	problem := Problem{
+		Position: Position{Filename: filename},
+		Message:  strings.TrimPrefix(message, "yaml: "),
+	}
+
+	if matches := lineRegex.FindStringSubmatch(message); matches != nil {
+		problem.Position.Line, _ = strconv.Atoi(matches[1])
+		problem.Message = matches[2]
+	}
+
+	return problem
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of names returned by suggest.
+const maxSuggestions = 3
+
+// suggest returns the known names that are closest to the given name. Names
+// that differ only by case are preferred, followed by names that differ only
+// by their package or receiver qualifier, followed by names within a small
+// edit distance. Ties are broken by edit distance.
+func suggest(name string, known map[string]struct{}) []string {
+	var (
+		best       []string
+		bestScore  = score{tier: 3}
+		lowerName  = strings.ToLower(name)
+		maxDist    = len(name)/3 + 1
+		candidates = make([]string, 0, len(known))
+	)
+
+	for candidate := range known {
+		candidates = append(candidates, candidate)
+	}
+
+	// Sort candidates so that suggestions are deterministic.
+	sort.Strings(candidates)
+
+	for _, candidate := range candidates {
+		var current score
+
+		switch lowerCandidate := strings.ToLower(candidate); {
+		case lowerCandidate == lowerName:
+			current = score{tier: 0}
+
+		case unqualified(lowerCandidate) == unqualified(lowerName):
+			current = score{tier: 1, dist: distance(lowerName, lowerCandidate)}
+
+		// Skip candidates whose lengths alone rule them out.
+		case abs(len(candidate)-len(name)) > maxDist:
+			continue
+
+		default:
+			dist := distance(lowerName, lowerCandidate)
+			if dist > maxDist {
+				continue
+			}
+			current = score{tier: 2, dist: dist}
+		}
+
+		switch {
+		case current.less(bestScore):
+			bestScore = current
+			best = []string{candidate}
+		case current == bestScore && len(best) < maxSuggestions:
+			best = append(best, candidate)
+		}
+	}
+
+	return best
+}
+
+// score ranks how closely a candidate name matches. Lower is better.
+type score struct {
+	tier int
+	dist int
+}
+
+func (s score) less(other score) bool {
+	if s.tier != other.tier {
+		return s.tier < other.tier
+	}
+	return s.dist < other.dist
+}
+
+// distance returns the Levenshtein edit distance between a and b.
+func distance(a string, b string) int {
+	var (
+		first  = []rune(a)
+		second = []rune(b)
+		prev   = make([]int, len(second)+1)
+		curr   = make([]int, len(second)+1)
+	)
+
+	for index := range prev {
+		prev[index] = index
+	}
+
+	for i := 1; i <= len(first); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(second); j++ {
+			cost := 1
+			if first[i-1] == second[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(second)]
+}
+
+// orList formats the given names as a quoted, human readable list.
+func orList(names []string) string {
+	quoted := make([]string, len(names))
+	for index, name := range names {
+		quoted[index] = fmt.Sprintf("%q", name)
+	}
+
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// unqualified strips the package and receiver qualifiers from a function name.
+func unqualified(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
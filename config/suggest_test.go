@@ -0,0 +1,69 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggest(t *testing.T) {
+
+	known := map[string]struct{}{
+		"fmt.Println":          {},
+		"fmt.Printf":           {},
+		"fmt.Sprintf":          {},
+		"os.Exit":              {},
+		"os.Getenv":            {},
+		"(*sync.Mutex).Lock":   {},
+		"(*sync.RWMutex).Lock": {},
+	}
+
+	tests := []struct {
+		title    string
+		name     string
+		expected []string
+	}{
+		{
+			title:    "wrong case",
+			name:     "fmt.println",
+			expected: []string{"fmt.Println"},
+		},
+		{
+			title:    "single typo",
+			name:     "os.Exot",
+			expected: []string{"os.Exit"},
+		},
+		{
+			title:    "equally close",
+			name:     "fmt.Printlf",
+			expected: []string{"fmt.Printf", "fmt.Println"},
+		},
+		{
+			title:    "receiver typo",
+			name:     "(*sync.Mutx).Lock",
+			expected: []string{"(*sync.Mutex).Lock"},
+		},
+		{
+			title:    "missing package",
+			name:     "Getenv",
+			expected: []string{"os.Getenv"},
+		},
+		{
+			title: "nothing close",
+			name:  "net/http.ListenAndServe",
+		},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.title)
+
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, suggest(test.name, known))
+		})
+	}
+}
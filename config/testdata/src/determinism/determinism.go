@@ -0,0 +1,16 @@
+package determinism
+
+import (
+	"math/rand"
+	"os"
+	"time"
+
+	"example.com/dep"
+)
+
+func Run() {
+	dep.Run()
+	rand.Int()        // want "determinism-global-random"
+	time.Now()        // want "determinism-wall-clock"
+	os.Getenv("HOME") // want "determinism-environment"
+}
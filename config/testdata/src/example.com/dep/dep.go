@@ -0,0 +1,23 @@
+package dep
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// Run calls a function forbidden by every built-in bundle, none of which may
+// be reported when checking the packages that import it.
+func Run() {
+	md5.Sum(nil)
+	rand.Read(nil)
+	rand.Int()
+	time.Now()
+	os.Getenv("HOME")
+	fmt.Println("dep")
+	ioutil.ReadFile("dep")
+	os.Exit(1)
+}
@@ -0,0 +1,16 @@
+package library
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"example.com/dep"
+)
+
+func Run() {
+	dep.Run()
+	fmt.Println("library")  // want "library-no-stdout"
+	ioutil.ReadFile("file") // want "library-no-deprecated"
+	os.Exit(1)              // want "library-no-exit"
+}
@@ -0,0 +1,14 @@
+package security
+
+import (
+	"crypto/md5"
+	"math/rand"
+
+	"example.com/dep"
+)
+
+func Run() {
+	dep.Run()
+	md5.Sum(nil)   // want "security-weak-hash"
+	rand.Read(nil) // want "security-insecure-random"
+}
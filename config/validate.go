@@ -0,0 +1,214 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/policy"
+)
+
+// Validate checks that every policy is well formed. Policies must have a
+// unique name, and a rule whose nodes either all have names or are
+// combinators.
+func (cfg *Config) Validate() Problems {
+	var (
+		problems Problems
+		seen     = make(map[string]Position)
+	)
+
+	for index, forbidden := range cfg.Forbidden {
+		pos := cfg.PolicyPosition(index)
+
+		if forbidden.Name == "" {
+			problems = append(problems, Problem{Position: pos, Message: "policy name is empty"})
+		} else if first, found := seen[forbidden.Name]; found {
+			problems = append(problems, Problem{Position: pos, Message: fmt.Sprintf("duplicate policy name %q (first defined at %s)", forbidden.Name, first)})
+		} else {
+			seen[forbidden.Name] = pos
+		}
+
+		if !forbidden.Scope.Valid() {
+			problems = append(problems, Problem{Position: pos, Message: fmt.Sprintf("policy %q has unknown scope %q", forbidden.Name, forbidden.Scope)})
+		}
+
+		patterns := [][]string{
+			forbidden.Include,
+			forbidden.Exclude,
+			forbidden.Intermediaries.Include,
+			forbidden.Intermediaries.Exclude,
+		}
+
+		for _, group := range patterns {
+			for _, pattern := range group {
+				if pattern == "" {
+					problems = append(problems, Problem{Position: pos, Message: fmt.Sprintf("policy %q has an empty include or exclude pattern", forbidden.Name)})
+				}
+			}
+		}
+
+		templates := []struct{ field, text string }{
+			{"message", forbidden.Message},
+			{"remediation", forbidden.Remediation},
+			{"url", forbidden.URL},
+		}
+
+		for _, tmpl := range templates {
+			if err := policy.CheckTemplate(tmpl.text); err != nil {
+				problems = append(problems, Problem{Position: pos, Message: fmt.Sprintf("policy %q has an invalid %s template: %v", forbidden.Name, tmpl.field, err)})
+			}
+		}
+
+		kinds := 0
+		for _, set := range []bool{forbidden.Rule != nil, forbidden.Pair != nil, forbidden.ForbidRecursion, forbidden.ForbidDeprecated} {
+			if set {
+				kinds++
+			}
+		}
+
+		if kinds > 1 {
+			problems = append(problems, Problem{Position: pos, Message: fmt.Sprintf("policy %q can only have one of rule, pair, forbid_recursion, or forbid_deprecated", forbidden.Name)})
+		}
+
+		if forbidden.Transitive && !forbidden.ForbidDeprecated {
+			problems = append(problems, Problem{Position: pos, Message: fmt.Sprintf("policy %q can only be transitive with forbid_deprecated", forbidden.Name)})
+		}
+
+		if forbidden.Pair != nil {
+			if forbidden.Pair.Open == "" || forbidden.Pair.Close == "" {
+				problems = append(problems, Problem{Position: pos, Message: fmt.Sprintf("policy %q pair must name both an open and a close function", forbidden.Name)})
+			}
+			continue
+		}
+
+		if forbidden.ForbidRecursion || forbidden.ForbidDeprecated {
+			continue
+		}
+
+		if forbidden.Rule == nil {
+			problems = append(problems, Problem{Position: pos, Message: fmt.Sprintf("policy %q has no rule", forbidden.Name)})
+			continue
+		}
+
+		forbidden.Rule.Walk(func(node *policy.Node) {
+			switch {
+			case node.Combinator() && node == forbidden.Rule:
+				problems = append(problems, Problem{Position: cfg.NodePosition(node), Message: "combinators are not allowed in the root of a rule"})
+			case node.Combinator() && (node.Name != "" || len(node.Calls) != 0):
+				problems = append(problems, Problem{Position: cfg.NodePosition(node), Message: "rule node cannot have a name or calls alongside any_of, all_of, or none_of"})
+			case node.Combinator() && combinators(node) != 1:
+				problems = append(problems, Problem{Position: cfg.NodePosition(node), Message: "rule node can only have one of any_of, all_of, or none_of"})
+			case node.Combinator():
+			case node.Name == "":
+				problems = append(problems, Problem{Position: cfg.NodePosition(node), Message: "rule name is empty"})
+			case node != forbidden.Rule && graph.IsGlob(node.Name):
+				problems = append(problems, Problem{Position: cfg.NodePosition(node), Message: fmt.Sprintf("rule name %q contains wildcards, which are only allowed in the root of a rule", node.Name)})
+			}
+
+			if !node.Order.Valid() {
+				problems = append(problems, Problem{Position: cfg.NodePosition(node), Message: fmt.Sprintf("rule has unknown order %q", node.Order)})
+			}
+
+			switch {
+			case node.MaxDepth < 0:
+				problems = append(problems, Problem{Position: cfg.NodePosition(node), Message: fmt.Sprintf("rule max_depth %d is negative", node.MaxDepth)})
+			case (node.Direct || node.MaxDepth != 0) && (node == forbidden.Rule || node.Combinator()):
+				problems = append(problems, Problem{Position: cfg.NodePosition(node), Message: "direct and max_depth are only allowed on named calls"})
+			}
+		})
+	}
+
+	return problems
+}
+
+// combinators returns the number of different combinators used by the given
+// node.
+func combinators(node *policy.Node) int {
+	count := 0
+	for _, children := range [][]*policy.Node{node.AnyOf, node.AllOf, node.NoneOf} {
+		if len(children) != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Resolve checks that every rule node name refers to a function that is
+// either declared or called somewhere in the given call graph. Names that do
+// not resolve are reported as warnings, along with any similarly named
+// functions that the author may have intended. Built-in bundles name many
+// functions that a program is not expected to use, so they are not checked.
+func (cfg *Config) Resolve(decls map[string]graph.FuncDecl) Problems {
+	var (
+		problems Problems
+		known    = knownNames(decls)
+	)
+
+	for index, forbidden := range cfg.Forbidden {
+		if cfg.PolicyPosition(index).Builtin() {
+			continue
+		}
+
+		forbidden.Rule.Walk(func(node *policy.Node) {
+			if node.Name == "" {
+				return
+			}
+
+			if problem, ok := unresolved(node.Name, cfg.NodePosition(node), known); !ok {
+				problems = append(problems, problem)
+			}
+		})
+
+		if forbidden.Pair != nil {
+			for _, name := range []string{forbidden.Pair.Open, forbidden.Pair.Close} {
+				if problem, ok := unresolved(name, cfg.PolicyPosition(index), known); !ok {
+					problems = append(problems, problem)
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// unresolved checks that the given rule name matches a known function. If it
+// does not, a warning is returned along with false.
+func unresolved(name string, pos Position, known map[string]struct{}) (Problem, bool) {
+	if _, found := known[name]; found {
+		return Problem{}, true
+	}
+
+	message := fmt.Sprintf("rule name %q does not match any function in the program", name)
+
+	// Wildcard names only need to match a single function.
+	if graph.IsGlob(name) {
+		pattern := graph.NewPattern(name)
+		for candidate := range known {
+			if pattern.MatchName(candidate) {
+				return Problem{}, true
+			}
+		}
+	} else if suggestions := suggest(name, known); len(suggestions) != 0 {
+		message += fmt.Sprintf("; did you mean %s?", orList(suggestions))
+	}
+
+	return Problem{Position: pos, Severity: Warning, Message: message}, false
+}
+
+// knownNames returns the name of every function that is declared or called in
+// the given call graph.
+func knownNames(decls map[string]graph.FuncDecl) map[string]struct{} {
+	known := make(map[string]struct{}, len(decls))
+
+	for name, decl := range decls {
+		known[name] = struct{}{}
+		for _, call := range decl.Calls {
+			known[call.Name] = struct{}{}
+		}
+	}
+
+	return known
+}
@@ -0,0 +1,145 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+// Package gitdiff determines which source lines have changed between a git
+// revision and the working tree.
+package gitdiff
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Range is an inclusive range of line numbers.
+type Range struct {
+	Start int
+	End   int
+}
+
+// Changes maps absolute filenames to the ranges of lines that have changed
+// in that file.
+type Changes map[string][]Range
+
+// Contains reports whether the given line of the named file has changed.
+func (changes Changes) Contains(filename string, line int) bool {
+	return changes.Overlaps(filename, line, line)
+}
+
+// Overlaps reports whether any line between start and end, inclusive, of the
+// named file has changed.
+func (changes Changes) Overlaps(filename string, start int, end int) bool {
+	ranges, found := changes[filename]
+	if !found {
+		// The file may have been loaded through a symlinked path, whereas git
+		// always reports paths relative to the real repository root.
+		if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+			ranges = changes[resolved]
+		}
+	}
+
+	for _, r := range ranges {
+		if r.Start <= end && start <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns the lines that have changed in the working tree since the
+// given revision, using the local git binary.
+func Diff(base string) (Changes, error) {
+	return diff("", base)
+}
+
+// diff is Diff, run in the given directory, or the current directory if
+// empty.
+func diff(dir string, base string) (Changes, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	// The prefixes are given explicitly, as they may otherwise be changed,
+	// or removed, by the diff.noprefix and diff.mnemonicPrefix settings.
+	output, err := git(dir, "diff", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", "--unified=0", base, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(strings.TrimSpace(string(root)), output)
+}
+
+func git(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
+}
+
+var hunkRegex = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// parse extracts the changed line ranges from the output of git diff, with
+// filenames made absolute relative to the repository root.
+func parse(root string, output []byte) (Changes, error) {
+	var (
+		changes = make(Changes)
+		current string
+		scanner = bufio.NewScanner(bytes.NewReader(output))
+	)
+
+	scanner.Buffer(nil, 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			current = ""
+
+			// Deleted files have no lines left to report.
+			if name := strings.TrimPrefix(line, "+++ "); name != "/dev/null" {
+				current = filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/")))
+			}
+
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			matches := hunkRegex.FindStringSubmatch(line)
+			if matches == nil {
+				return nil, fmt.Errorf("malformed hunk header %q", line)
+			}
+
+			start, _ := strconv.Atoi(matches[1])
+			count := 1
+			if matches[2] != "" {
+				count, _ = strconv.Atoi(matches[2])
+			}
+
+			// A hunk that only removes lines is recorded as a change to the
+			// line preceding the removal.
+			if count == 0 {
+				count = 1
+				if start == 0 {
+					start = 1
+				}
+			}
+
+			changes[current] = append(changes[current], Range{start, start + count - 1})
+		}
+	}
+
+	return changes, scanner.Err()
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package gitdiff
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	output := `
+		diff --git a/main.go b/main.go
+		index 1111111..2222222 100644
+		--- a/main.go
+		+++ b/main.go
+		@@ -3,0 +4,2 @@ import (
+		+	"os"
+		+	"fmt"
+		@@ -10 +12 @@ func main() {
+		-	run()
+		+	exec()
+		@@ -20,3 +21,0 @@ func run() {
+		-	a()
+		-	b()
+		-	c()
+		diff --git a/old.go b/old.go
+		deleted file mode 100644
+		--- a/old.go
+		+++ /dev/null
+		@@ -1,3 +0,0 @@
+		-package main
+		diff --git a/pkg/new.go b/pkg/new.go
+		new file mode 100644
+		--- /dev/null
+		+++ b/pkg/new.go
+		@@ -0,0 +1,5 @@
+		+package pkg
+	`
+
+	changes, err := parse("/repo", []byte(strings.Replace(output, "\t\t", "", -1)))
+	require.NoError(t, err)
+
+	assert.Equal(t, Changes{
+		"/repo/main.go": {
+			{Start: 4, End: 5},
+			{Start: 12, End: 12},
+			{Start: 21, End: 21},
+		},
+		"/repo/pkg/new.go": {
+			{Start: 1, End: 5},
+		},
+	}, changes)
+
+	assert.True(t, changes.Contains("/repo/main.go", 12))
+	assert.False(t, changes.Contains("/repo/main.go", 13))
+	assert.True(t, changes.Overlaps("/repo/main.go", 6, 12))
+	assert.False(t, changes.Overlaps("/repo/main.go", 6, 11))
+	assert.False(t, changes.Contains("/repo/old.go", 1))
+}
+
+func TestDiffPrefixes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tests := []struct {
+		title  string
+		config []string
+	}{
+		{
+			title: "default prefixes",
+		},
+		{
+			title:  "no prefix",
+			config: []string{"diff.noprefix", "true"},
+		},
+		{
+			title:  "mnemonic prefix",
+			config: []string{"diff.mnemonicPrefix", "true"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			dir, err := filepath.EvalSymlinks(t.TempDir())
+			require.NoError(t, err)
+
+			run := func(args ...string) {
+				_, err := git(dir, args...)
+				require.NoError(t, err)
+			}
+
+			filename := filepath.Join(dir, "main.go")
+
+			run("init", "--quiet")
+			require.NoError(t, os.WriteFile(filename, []byte("package main\n\nfunc main() {}\n"), 0644))
+			run("add", "main.go")
+			run("-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false", "commit", "--quiet", "-m", "initial")
+
+			if test.config != nil {
+				run(append([]string{"config"}, test.config...)...)
+			}
+
+			require.NoError(t, os.WriteFile(filename, []byte("package main\n\nfunc main() { run() }\n"), 0644))
+
+			changes, err := diff(dir, "HEAD")
+			require.NoError(t, err)
+
+			assert.Equal(t, Changes{
+				filename: {
+					{Start: 3, End: 3},
+				},
+			}, changes)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"sort"
+)
+
+// Cycles returns the strongly connected components of the given call graph
+// that contain a cycle, meaning every group of functions that are mutually
+// recursive, along with every function that calls itself directly. The names
+// in each component are sorted, and components are sorted by their first name.
+func Cycles(decls map[string]FuncDecl) [][]string {
+	components := components(decls, false)
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+
+	return components
+}
+
+// components returns the strongly connected components of the given call
+// graph, with their names sorted. Every component is returned after all of
+// the components that it calls. Unless trivial is set, components that do not
+// contain a cycle are omitted.
+func components(decls map[string]FuncDecl, trivial bool) [][]string {
+	names := make([]string, 0, len(decls))
+	for name := range decls {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	t := tarjan{
+		decls:   decls,
+		trivial: trivial,
+		index:   make(map[string]int, len(decls)),
+		lowlink: make(map[string]int, len(decls)),
+		onStack: make(map[string]bool, len(decls)),
+	}
+
+	for _, name := range names {
+		if _, found := t.index[name]; !found {
+			t.connect(name)
+		}
+	}
+
+	return t.components
+}
+
+// tarjan finds strongly connected components using Tarjan's algorithm.
+type tarjan struct {
+	decls      map[string]FuncDecl
+	trivial    bool
+	counter    int
+	index      map[string]int
+	lowlink    map[string]int
+	stack      []string
+	onStack    map[string]bool
+	components [][]string
+}
+
+func (t *tarjan) connect(name string) {
+	t.index[name] = t.counter
+	t.lowlink[name] = t.counter
+	t.counter++
+
+	t.stack = append(t.stack, name)
+	t.onStack[name] = true
+
+	selfCall := false
+
+	for _, call := range t.decls[name].Calls {
+		// Only declared functions can take part in a cycle.
+		if _, found := t.decls[call.Name]; !found {
+			continue
+		}
+
+		if call.Name == name {
+			selfCall = true
+		}
+
+		if _, found := t.index[call.Name]; !found {
+			t.connect(call.Name)
+			t.lowlink[name] = min(t.lowlink[name], t.lowlink[call.Name])
+		} else if t.onStack[call.Name] {
+			t.lowlink[name] = min(t.lowlink[name], t.index[call.Name])
+		}
+	}
+
+	if t.lowlink[name] != t.index[name] {
+		return
+	}
+
+	var component []string
+	for {
+		top := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[top] = false
+
+		component = append(component, top)
+		if top == name {
+			break
+		}
+	}
+
+	// A lone function is only a cycle if it calls itself.
+	if len(component) == 1 && !selfCall && !t.trivial {
+		return
+	}
+
+	sort.Strings(component)
+	t.components = append(t.components, component)
+}
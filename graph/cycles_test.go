@@ -0,0 +1,55 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycles(t *testing.T) {
+	cg := map[string]FuncDecl{
+		"a.main": {
+			Name: "a.main",
+			Calls: []FuncCall{
+				{Name: "a.even"},
+				{Name: "a.fact"},
+				{Name: "a.run"},
+			},
+		},
+		"a.even": {
+			Name: "a.even",
+			Calls: []FuncCall{
+				{Name: "b.odd"},
+			},
+		},
+		"b.odd": {
+			Name: "b.odd",
+			Calls: []FuncCall{
+				{Name: "a.even"},
+			},
+		},
+		"a.fact": {
+			Name: "a.fact",
+			Calls: []FuncCall{
+				{Name: "a.fact"},
+			},
+		},
+		"a.run": {
+			Name: "a.run",
+			Calls: []FuncCall{
+				{Name: "panic"},
+			},
+		},
+	}
+
+	expected := [][]string{
+		{"a.even", "b.odd"},
+		{"a.fact"},
+	}
+
+	assert.Equal(t, expected, Cycles(cg))
+}
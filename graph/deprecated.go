@@ -0,0 +1,30 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// deprecatedPrefix starts the paragraph of a doc comment that marks a
+// declaration as deprecated.
+const deprecatedPrefix = "Deprecated: "
+
+// deprecation returns the deprecation notice in the given doc comment, with
+// its lines joined, or an empty string if there is none.
+func deprecation(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	for _, paragraph := range strings.Split(doc.Text(), "\n\n") {
+		if strings.HasPrefix(paragraph, deprecatedPrefix) {
+			return strings.Join(strings.Fields(strings.TrimPrefix(paragraph, deprecatedPrefix)), " ")
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deprecatedSource = `package example
+
+func none() {}
+
+// plain does nothing.
+func plain() {}
+
+// single does nothing.
+//
+// Deprecated: Use plain instead.
+func single() {}
+
+// multi does nothing.
+//
+// Deprecated: multi is no longer supported,
+// and will be removed.
+//
+// It used to do something.
+func multi() {}
+
+// inline mentions Deprecated: in passing.
+func inline() {}
+`
+
+func TestDeprecation(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "example.go", deprecatedSource, parser.ParseComments)
+	require.NoError(t, err)
+
+	notices := make(map[string]string)
+	for _, decl := range file.Decls {
+		fn := decl.(*ast.FuncDecl)
+		notices[fn.Name.Name] = deprecation(fn.Doc)
+	}
+
+	assert.Equal(t, map[string]string{
+		"none":   "",
+		"plain":  "",
+		"single": "Use plain instead.",
+		"multi":  "multi is no longer supported, and will be removed.",
+		"inline": "",
+	}, notices)
+}
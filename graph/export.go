@@ -0,0 +1,219 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// node is a function that appears in an exported graph. Functions that are
+// called but not declared, such as builtins, have no position.
+type node struct {
+	Name     string `json:"name"`
+	Package  string `json:"package,omitempty"`
+	Position string `json:"position,omitempty"`
+}
+
+// edge is a single call site that appears in an exported graph.
+type edge struct {
+	Caller   string `json:"caller"`
+	Callee   string `json:"callee"`
+	Position string `json:"position"`
+}
+
+// flatten returns every node and edge in the given graph, sorted by name and
+// position respectively, so that exports are deterministic.
+func flatten(decls map[string]FuncDecl) ([]node, []edge) {
+	var (
+		nodes = make(map[string]node)
+		edges = []edge{}
+	)
+
+	for name, decl := range decls {
+		nodes[name] = node{decl.Name, decl.Package, decl.Position}
+
+		for _, call := range decl.Calls {
+			if _, found := nodes[call.Name]; !found {
+				nodes[call.Name] = node{Name: call.Name, Package: call.Package}
+			}
+
+			edges = append(edges, edge{name, call.Name, call.Position})
+		}
+	}
+
+	sortedNodes := make([]node, 0, len(nodes))
+	for name, n := range nodes {
+		// Prefer the declaration of a function over a call to it.
+		if decl, found := decls[name]; found {
+			n = node{decl.Name, decl.Package, decl.Position}
+		}
+		sortedNodes = append(sortedNodes, n)
+	}
+
+	sort.Slice(sortedNodes, func(i, j int) bool {
+		return sortedNodes[i].Name < sortedNodes[j].Name
+	})
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Caller != edges[j].Caller {
+			return edges[i].Caller < edges[j].Caller
+		}
+		return LessPosition(edges[i].Position, edges[j].Position)
+	})
+
+	return sortedNodes, edges
+}
+
+// WriteJSON writes the given graph as a JSON object containing a list of
+// functions and a list of call sites.
+func WriteJSON(w io.Writer, decls map[string]FuncDecl) error {
+	nodes, edges := flatten(decls)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(struct {
+		Nodes []node `json:"nodes"`
+		Edges []edge `json:"edges"`
+	}{nodes, edges})
+}
+
+// WriteDOT writes the given graph in the Graphviz DOT language. Multiple
+// calls between the same pair of functions are drawn as a single edge.
+func WriteDOT(w io.Writer, decls map[string]FuncDecl) error {
+	nodes, edges := flatten(decls)
+
+	if _, err := fmt.Fprintln(w, "digraph callgraph {"); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\tnode [shape=box];")
+
+	for _, n := range nodes {
+		style := ""
+		if n.Position == "" {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(w, "\t%s [label=%s%s];\n", strconv.Quote(n.Name), strconv.Quote(n.Name), style)
+	}
+
+	var (
+		counts = make(map[[2]string]int)
+		order  [][2]string
+	)
+
+	for _, e := range edges {
+		pair := [2]string{e.Caller, e.Callee}
+		if counts[pair] == 0 {
+			order = append(order, pair)
+		}
+		counts[pair]++
+	}
+
+	for _, pair := range order {
+		label := ""
+		if count := counts[pair]; count > 1 {
+			label = fmt.Sprintf(" [label=\"%d\"]", count)
+		}
+		fmt.Fprintf(w, "\t%s -> %s%s;\n", strconv.Quote(pair[0]), strconv.Quote(pair[1]), label)
+	}
+
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+type graphml struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+type graphmlKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphmlGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the given graph as a GraphML document, with one edge
+// for every call site.
+func WriteGraphML(w io.Writer, decls map[string]FuncDecl) error {
+	nodes, edges := flatten(decls)
+
+	doc := graphml{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphmlKey{
+			{"package", "node", "package", "string"},
+			{"position", "node", "position", "string"},
+			{"callsite", "edge", "position", "string"},
+		},
+		Graph: graphmlGraph{
+			ID:          "callgraph",
+			EdgeDefault: "directed",
+		},
+	}
+
+	for _, n := range nodes {
+		var data []graphmlData
+		if n.Package != "" {
+			data = append(data, graphmlData{"package", n.Package})
+		}
+		if n.Position != "" {
+			data = append(data, graphmlData{"position", n.Position})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{n.Name, data})
+	}
+
+	for _, e := range edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			Source: e.Caller,
+			Target: e.Callee,
+			Data:   []graphmlData{{"callsite", e.Position}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestExport(t *testing.T) {
+
+	graphs := map[string]map[string]FuncDecl{
+		"empty": nil,
+		"callgraph": {
+			"a.main": {
+				Name:     "a.main",
+				Package:  "a",
+				Position: "main.go:3:1",
+				Calls: []FuncCall{
+					{Name: "os.Exit", Package: "os", Position: "main.go:6:2"},
+					{Name: "a.run", Package: "a", Position: "main.go:5:2"},
+					{Name: "a.run", Package: "a", Position: "main.go:4:2"},
+				},
+			},
+			"a.run": {
+				Name:     "a.run",
+				Package:  "a",
+				Position: "run.go:3:1",
+				Calls: []FuncCall{
+					{Name: "panic", Position: "run.go:4:2"},
+				},
+			},
+		},
+	}
+
+	formats := map[string]func(io.Writer, map[string]FuncDecl) error{
+		"dot":     WriteDOT,
+		"graphml": WriteGraphML,
+		"json":    WriteJSON,
+	}
+
+	tests := []struct {
+		graph  string
+		format string
+	}{
+		{graph: "empty", format: "dot"},
+		{graph: "empty", format: "graphml"},
+		{graph: "empty", format: "json"},
+		{graph: "callgraph", format: "dot"},
+		{graph: "callgraph", format: "graphml"},
+		{graph: "callgraph", format: "json"},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s as %s", index, test.graph, test.format)
+
+		t.Run(name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			require.NoError(t, formats[test.format](&buffer, graphs[test.graph]))
+
+			golden := filepath.Join("testdata", "export", test.graph+"."+test.format)
+			if *update {
+				require.NoError(t, os.WriteFile(golden, buffer.Bytes(), 0644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(expected), buffer.String())
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// noReturn lists the functions that never return to their caller.
+var noReturn = map[string]struct{}{
+	"panic":          {},
+	"os.Exit":        {},
+	"runtime.Goexit": {},
+	"log.Fatal":      {},
+	"log.Fatalf":     {},
+	"log.Fatalln":    {},
+	"log.Panic":      {},
+	"log.Panicf":     {},
+	"log.Panicln":    {},
+}
+
+// flow builds the control flow graph of the given function body. It returns
+// the successors of every block, the blocks that return normally, and the
+// block that each call is made in. Calls made inside of function literals do
+// not belong to any block.
+func flow(info *types.Info, body *ast.BlockStmt) ([][]int, []int, map[*ast.CallExpr]int) {
+	graph := cfg.New(body, func(call *ast.CallExpr) bool {
+		_, name, ok := qualify(info, call)
+		if !ok {
+			return true
+		}
+		_, found := noReturn[name]
+		return !found
+	})
+
+	var (
+		blocks  = make([][]int, len(graph.Blocks))
+		returns []int
+		calls   = make(map[*ast.CallExpr]int)
+	)
+
+	for _, block := range graph.Blocks {
+		succs := make([]int, len(block.Succs))
+		for index, succ := range block.Succs {
+			succs[index] = int(succ.Index)
+		}
+		blocks[block.Index] = succs
+
+		// Every normal return is materialized as a return statement at the
+		// end of a block, even when implicit.
+		if len(block.Succs) == 0 && len(block.Nodes) != 0 {
+			if _, ok := block.Nodes[len(block.Nodes)-1].(*ast.ReturnStmt); ok {
+				returns = append(returns, int(block.Index))
+			}
+		}
+
+		for _, node := range block.Nodes {
+			ast.Inspect(node, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.CallExpr:
+					calls[node] = int(block.Index)
+				}
+				return true
+			})
+		}
+	}
+
+	return blocks, returns, calls
+}
+
+// deferredCalls returns every call in the given function body that is
+// deferred, including the calls made directly inside of deferred function
+// literals, but not those made inside of any other function literal. Each
+// call is mapped to the call of the defer statement that defers it.
+func deferredCalls(body *ast.BlockStmt) map[*ast.CallExpr]*ast.CallExpr {
+	deferred := make(map[*ast.CallExpr]*ast.CallExpr)
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		var stmt *ast.DeferStmt
+
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			// Calls deferred inside of other function literals, such as
+			// those run as goroutines, are deferred until the literal
+			// returns rather than this function.
+			return false
+		case *ast.DeferStmt:
+			stmt = node
+		default:
+			return true
+		}
+
+		deferred[stmt.Call] = stmt.Call
+
+		if lit, ok := stmt.Call.Fun.(*ast.FuncLit); ok {
+			ast.Inspect(lit.Body, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.FuncLit:
+					return false
+				case *ast.CallExpr:
+					deferred[node] = stmt.Call
+				}
+				return true
+			})
+		}
+
+		return true
+	})
+
+	return deferred
+}
+
+// blocks returns the control flow blocks of the calls at the given indices in
+// Calls. The returned bool is false if either block is unknown.
+func (decl FuncDecl) blocks(from int, to int) (int, int, bool) {
+	if decl.Blocks == nil || from >= len(decl.Calls) || to >= len(decl.Calls) {
+		return 0, 0, false
+	}
+
+	a, b := decl.Calls[from].Block, decl.Calls[to].Block
+	if a < 0 || b < 0 || a >= len(decl.Blocks) || b >= len(decl.Blocks) {
+		return 0, 0, false
+	}
+
+	return a, b, true
+}
+
+// Reachable reports whether the call at index to in Calls may be made after
+// the call at index from, along some path through the function. The second
+// result is false if the control flow of either call is unknown.
+func (decl FuncDecl) Reachable(from int, to int) (bool, bool) {
+	a, b, ok := decl.blocks(from, to)
+	if !ok {
+		return false, false
+	}
+
+	// Calls in the same block are made in order.
+	if a == b && from < to {
+		return true, true
+	}
+
+	var (
+		visited = make(map[int]struct{})
+		queue   = append([]int{}, decl.Blocks[a]...)
+	)
+
+	for len(queue) != 0 {
+		block := queue[0]
+		queue = queue[1:]
+
+		if block == b {
+			return true, true
+		}
+
+		if _, found := visited[block]; found {
+			continue
+		}
+		visited[block] = struct{}{}
+
+		queue = append(queue, decl.Blocks[block]...)
+	}
+
+	return false, true
+}
+
+// PostDominates reports whether the call at index to in Calls is made on
+// every path from the call at index from to the end of the function. The
+// second result is false if the control flow of either call is unknown.
+func (decl FuncDecl) PostDominates(from int, to int) (bool, bool) {
+	a, b, ok := decl.blocks(from, to)
+	if !ok {
+		return false, false
+	}
+
+	// Calls in the same block are made in order.
+	if a == b && from < to {
+		return true, true
+	}
+
+	// Search for a path to the end of the function that avoids the block
+	// of the second call.
+	var (
+		visited = make(map[int]struct{})
+		queue   = append([]int{}, decl.Blocks[a]...)
+	)
+
+	if len(queue) == 0 {
+		return false, true
+	}
+
+	for len(queue) != 0 {
+		block := queue[0]
+		queue = queue[1:]
+
+		if block == b {
+			continue
+		}
+
+		if _, found := visited[block]; found {
+			continue
+		}
+		visited[block] = struct{}{}
+
+		if len(decl.Blocks[block]) == 0 {
+			return false, true
+		}
+
+		queue = append(queue, decl.Blocks[block]...)
+	}
+
+	return true, true
+}
+
+// Followed reports whether every path from the call at index from in Calls,
+// that returns normally from the function, makes a call that matches. A
+// matching call that is deferred is made on a path if its defer statement is
+// executed on that path. The second result is false if the control flow of
+// the call is unknown.
+func (decl FuncDecl) Followed(from int, match func(FuncCall) bool) (bool, bool) {
+	if decl.Blocks == nil || from >= len(decl.Calls) || decl.Calls[from].Block < 0 {
+		return false, false
+	}
+
+	start := decl.Calls[from].Block
+	covered := make(map[int]struct{})
+
+	for index, call := range decl.Calls {
+		if !match(call) || call.Block < 0 {
+			continue
+		}
+
+		// A matching call later in the same block follows on every path, as
+		// does one deferred anywhere in the same block.
+		if call.Block == start && (index > from || call.Deferred) {
+			return true, true
+		}
+
+		covered[call.Block] = struct{}{}
+	}
+
+	returns := make(map[int]struct{}, len(decl.Returns))
+	for _, block := range decl.Returns {
+		returns[block] = struct{}{}
+	}
+
+	if _, found := returns[start]; found {
+		return false, true
+	}
+
+	// Search for a path to a normal return that avoids every block which
+	// makes a matching call.
+	var (
+		visited = make(map[int]struct{})
+		queue   = append([]int{}, decl.Blocks[start]...)
+	)
+
+	for len(queue) != 0 {
+		block := queue[0]
+		queue = queue[1:]
+
+		if _, found := covered[block]; found {
+			continue
+		}
+
+		if _, found := visited[block]; found {
+			continue
+		}
+		visited[block] = struct{}{}
+
+		if _, found := returns[block]; found {
+			return false, true
+		}
+
+		queue = append(queue, decl.Blocks[block]...)
+	}
+
+	return true, true
+}
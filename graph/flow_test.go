@@ -0,0 +1,201 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const flowSource = `package p
+
+func a() {}
+func c() {}
+func d() {}
+
+func branch(b bool) {
+	a()
+	if b {
+		c()
+		return
+	}
+	d()
+}
+
+func loop() {
+	for i := 0; i < 3; i++ {
+		c()
+		a()
+	}
+	d()
+}
+
+func closure() {
+	a()
+	func() {
+		c()
+	}()
+}
+
+func early(b bool) {
+	a()
+	if b {
+		return
+	}
+	d()
+}
+
+func both(b bool) {
+	a()
+	if b {
+		d()
+		return
+	}
+	d()
+}
+
+func deferred() {
+	a()
+	defer d()
+}
+
+func deferredLiteral() {
+	a()
+	defer func() {
+		d()
+	}()
+}
+
+func conditionalDefer(b bool) {
+	if b {
+		defer d()
+	}
+	a()
+}
+
+func deferredFirst() {
+	defer d()
+	a()
+}
+
+func panics(b bool) {
+	a()
+	if b {
+		panic("b")
+	}
+	d()
+}
+`
+
+func TestFlow(t *testing.T) {
+	decls := flowDecls(t)
+
+	tests := []struct {
+		decl          string
+		from          string
+		to            string
+		reachable     bool
+		postDominates bool
+		known         bool
+	}{
+		{decl: "p.branch", from: "p.a", to: "p.c", reachable: true, postDominates: false, known: true},
+		{decl: "p.branch", from: "p.a", to: "p.d", reachable: true, postDominates: false, known: true},
+		{decl: "p.branch", from: "p.c", to: "p.d", reachable: false, postDominates: false, known: true},
+		{decl: "p.loop", from: "p.c", to: "p.a", reachable: true, postDominates: true, known: true},
+		{decl: "p.loop", from: "p.a", to: "p.c", reachable: true, postDominates: false, known: true},
+		{decl: "p.loop", from: "p.a", to: "p.d", reachable: true, postDominates: true, known: true},
+		{decl: "p.closure", from: "p.a", to: "p.c", known: false},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s from %s to %s", index, test.decl, test.from, test.to)
+
+		t.Run(name, func(t *testing.T) {
+			decl := decls[test.decl]
+			from, to := callIndex(decl, test.from), callIndex(decl, test.to)
+			require.True(t, from >= 0 && to >= 0)
+
+			reachable, known := decl.Reachable(from, to)
+			assert.Equal(t, test.known, known)
+			assert.Equal(t, test.reachable, reachable)
+
+			postDominates, known := decl.PostDominates(from, to)
+			assert.Equal(t, test.known, known)
+			assert.Equal(t, test.postDominates, postDominates)
+		})
+	}
+}
+
+// flowDecls builds a call graph from flowSource.
+func flowDecls(t *testing.T) map[string]FuncDecl {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "p.go", flowSource, 0)
+	require.NoError(t, err)
+
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+
+	config := types.Config{Importer: importer.Default()}
+	_, err = config.Check("p", fset, []*ast.File{file}, info)
+	require.NoError(t, err)
+
+	decls := Package(fset, info, []*ast.File{file})
+
+	return decls
+}
+
+func callIndex(decl FuncDecl, name string) int {
+	for index, call := range decl.Calls {
+		if call.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+func TestFollowed(t *testing.T) {
+	decls := flowDecls(t)
+
+	tests := []struct {
+		decl     string
+		followed bool
+	}{
+		{decl: "p.branch", followed: false},
+		{decl: "p.loop", followed: true},
+		{decl: "p.early", followed: false},
+		{decl: "p.both", followed: true},
+		{decl: "p.deferred", followed: true},
+		{decl: "p.deferredLiteral", followed: true},
+		{decl: "p.conditionalDefer", followed: false},
+		{decl: "p.deferredFirst", followed: true},
+		{decl: "p.panics", followed: true},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.decl)
+
+		t.Run(name, func(t *testing.T) {
+			decl := decls[test.decl]
+
+			followed, known := decl.Followed(callIndex(decl, "p.a"), func(call FuncCall) bool {
+				return call.Name == "p.d"
+			})
+
+			assert.True(t, known)
+			assert.Equal(t, test.followed, followed)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+// Focus returns the subgraph of decls surrounding every function matched by
+// one of the given patterns. Functions within depth calls of a matched
+// function, in either direction, are included. A negative depth places no
+// limit on the distance. Calls to functions outside of the subgraph are
+// removed.
+func Focus(decls map[string]FuncDecl, patterns []Pattern, depth int) map[string]FuncDecl {
+	var (
+		callers  = Callers(decls)
+		included = make(map[string]struct{})
+		frontier []string
+	)
+
+	matches := func(name string, pkg string) {
+		if _, found := included[name]; found {
+			return
+		}
+
+		for _, pattern := range patterns {
+			if pattern.Match(name, pkg) {
+				included[name] = struct{}{}
+				frontier = append(frontier, name)
+				return
+			}
+		}
+	}
+
+	for name, decl := range decls {
+		matches(name, decl.Package)
+		for _, call := range decl.Calls {
+			matches(call.Name, call.Package)
+		}
+	}
+
+	for distance := 0; len(frontier) != 0 && (depth < 0 || distance < depth); distance++ {
+		var next []string
+
+		visit := func(name string) {
+			if _, found := included[name]; !found {
+				included[name] = struct{}{}
+				next = append(next, name)
+			}
+		}
+
+		for _, name := range frontier {
+			for _, call := range decls[name].Calls {
+				visit(call.Name)
+			}
+			for _, caller := range callers[name] {
+				visit(caller.Name)
+			}
+		}
+
+		frontier = next
+	}
+
+	focused := make(map[string]FuncDecl, len(included))
+
+	for name := range included {
+		decl, found := decls[name]
+		if !found {
+			continue
+		}
+
+		calls := make([]FuncCall, 0, len(decl.Calls))
+		for _, call := range decl.Calls {
+			if _, found := included[call.Name]; found {
+				calls = append(calls, call)
+			}
+		}
+
+		decl.Calls = calls
+		focused[name] = decl
+	}
+
+	return focused
+}
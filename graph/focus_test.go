@@ -0,0 +1,101 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFocus(t *testing.T) {
+
+	cg := map[string]FuncDecl{
+		"a.main": {
+			Name:    "a.main",
+			Package: "a",
+			Calls: []FuncCall{
+				{Name: "a.run", Package: "a"},
+			},
+		},
+		"a.run": {
+			Name:    "a.run",
+			Package: "a",
+			Calls: []FuncCall{
+				{Name: "b/c.Exec", Package: "b/c"},
+			},
+		},
+		"b/c.Exec": {
+			Name:    "b/c.Exec",
+			Package: "b/c",
+			Calls: []FuncCall{
+				{Name: "panic"},
+			},
+		},
+		"b.Unused": {
+			Name:    "b.Unused",
+			Package: "b",
+		},
+	}
+
+	tests := []struct {
+		title    string
+		patterns []string
+		depth    int
+		expected []string
+	}{
+		{
+			title:    "exact name",
+			patterns: []string{"a.run"},
+			depth:    0,
+			expected: []string{"a.run"},
+		},
+		{
+			title:    "callers and callees",
+			patterns: []string{"a.run"},
+			depth:    1,
+			expected: []string{"a.main", "a.run", "b/c.Exec"},
+		},
+		{
+			title:    "name glob",
+			patterns: []string{"a.*"},
+			depth:    0,
+			expected: []string{"a.main", "a.run"},
+		},
+		{
+			title:    "package pattern",
+			patterns: []string{"b/..."},
+			depth:    0,
+			expected: []string{"b.Unused", "b/c.Exec"},
+		},
+		{
+			title:    "unlimited depth",
+			patterns: []string{"panic"},
+			depth:    -1,
+			expected: []string{"a.main", "a.run", "b/c.Exec"},
+		},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.title)
+
+		t.Run(name, func(t *testing.T) {
+			var patterns []Pattern
+			for _, pattern := range test.patterns {
+				patterns = append(patterns, NewPattern(pattern))
+			}
+
+			var actual []string
+			for name := range Focus(cg, patterns, test.depth) {
+				actual = append(actual, name)
+			}
+			sort.Strings(actual)
+
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -6,6 +6,9 @@ package graph
 
 import (
 	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
 
 	"golang.org/x/tools/go/loader"
 )
@@ -14,13 +17,90 @@ type FuncDecl struct {
 	Name     string
 	Package  string
 	Position string
-	Calls    []FuncCall
+
+	// End is the position immediately after the end of the function
+	// declaration.
+	End   string
+	Calls []FuncCall
+
+	// Method is the name and signature of this function if it is a method
+	// of a concrete type, so that it can be matched against calls made
+	// through interfaces.
+	Method string
+
+	// References lists the names of the functions that this function refers
+	// to other than by calling them, such as callbacks passed as arguments
+	// or method values. Each function is listed once.
+	References []string
+
+	// Blocks lists the successors of every block in the control flow graph
+	// of the function body, indexed by block. The first block is the entry
+	// point, and blocks without successors leave the function. Nil if the
+	// control flow of the function is unknown.
+	Blocks [][]int
+
+	// Returns lists the blocks that return normally from the function, as
+	// opposed to those that panic or otherwise never return.
+	Returns []int
+
+	// Configs lists the build configurations in which this function is
+	// declared. An empty list means that the function is declared in every
+	// configuration.
+	Configs []string
+
+	// Test is true if this function is declared in a _test.go file.
+	Test bool
+
+	// Initial is true if this function is declared in one of the packages
+	// that were named when the program was loaded, rather than in one of
+	// their dependencies.
+	Initial bool
+
+	// Deprecated is the text of the deprecation notice in the doc comment
+	// of this function, if any, without its "Deprecated:" prefix.
+	Deprecated string
+
+	// Alternates lists the declarations of this function in the build
+	// configurations where it is declared somewhere other than Position.
+	Alternates []Declaration
+}
+
+// Declaration is the extent of a single declaration of a function.
+type Declaration struct {
+	Position string
+	End      string
+
+	// Configs lists the build configurations in which the function is
+	// declared here.
+	Configs []string
 }
 
 type FuncCall struct {
 	Name     string
 	Package  string
 	Position string
+
+	// Block is the index of the control flow block, in the Blocks of the
+	// calling function, that this call is made in. Deferred calls belong to
+	// the block of their defer statement. Negative if unknown.
+	Block int
+
+	// Deferred is true if this call is deferred until the calling function
+	// returns, either directly or from within a deferred function literal.
+	Deferred bool
+
+	// Method is the name and signature of the method called, if this call
+	// is made through an interface, and so may be dispatched to any method
+	// with the same Method.
+	Method string
+
+	// Literal is true if this call is made from within a function literal,
+	// rather than directly from the body of the calling function.
+	Literal bool
+
+	// Configs lists the build configurations in which this call is made. An
+	// empty list means that the call is made in every configuration.
+	Configs []string
 }
 
 func Program(program *loader.Program) (map[string]FuncDecl, error) {
@@ -28,40 +108,105 @@ func Program(program *loader.Program) (map[string]FuncDecl, error) {
 
 	// Check every package that belongs to the program.
 	for _, pkgInfo := range program.AllPackages {
-		// Check every file that belongs to the package.
-		for _, astFile := range pkgInfo.Files {
-			// Check every function declared in the file.
-			for _, f := range astFile.Decls {
-				fn, ok := f.(*ast.FuncDecl)
-				if !ok {
-					continue
-				}
-
-				// Attempt to fully qualify the function declaration name and package.
-				pkgName, name, ok := Qualify(pkgInfo, fn)
-				if !ok {
-					continue
-				}
-
-				decls[name] = FuncDecl{
-					Name:     name,
-					Package:  pkgName,
-					Position: program.Fset.Position(fn.Pos()).String(),
-					Calls:    []FuncCall{},
-				}
-
-				vis := funcDeclVisitor{
-					pkgInfo,
-					program.Fset,
-					name,
-					decls,
-				}
-
-				// Walk contents of the function declaration
-				ast.Walk(&vis, fn)
-			}
-		}
+		addPackage(decls, program.Fset, &pkgInfo.Info, pkgInfo.Files)
 	}
 
+	MarkInitial(decls, InitialPackages(program))
+
 	return decls, nil
 }
+
+// InitialPackages returns the import path of every package that the given
+// program was loaded from, rather than loaded as a dependency.
+func InitialPackages(program *loader.Program) map[string]struct{} {
+	paths := make(map[string]struct{})
+	for _, pkgInfo := range program.InitialPackages() {
+		paths[pkgInfo.Pkg.Path()] = struct{}{}
+	}
+	return paths
+}
+
+// MarkInitial sets Initial on every function declared in one of the packages
+// with the given import paths, and clears it on every other function.
+func MarkInitial(decls map[string]FuncDecl, paths map[string]struct{}) {
+	for name, decl := range decls {
+		_, decl.Initial = paths[decl.Package]
+		decls[name] = decl
+	}
+}
+
+// Package builds a call graph containing only the functions declared in the
+// given files, which must all belong to a single type checked package. Calls
+// to functions declared in other packages are recorded, but those functions
+// will not appear in the returned graph.
+func Package(fset *token.FileSet, info *types.Info, files []*ast.File) map[string]FuncDecl {
+	decls := make(map[string]FuncDecl)
+	addPackage(decls, fset, info, files)
+	return decls
+}
+
+func addPackage(decls map[string]FuncDecl, fset *token.FileSet, info *types.Info, files []*ast.File) {
+	// Check every file that belongs to the package.
+	for _, astFile := range files {
+		// Check every function declared in the file.
+		for _, f := range astFile.Decls {
+			fn, ok := f.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+
+			// Attempt to fully qualify the function declaration name and package.
+			pkgName, name, ok := qualify(info, fn)
+			if !ok {
+				continue
+			}
+
+			position := fset.Position(fn.Pos())
+
+			var (
+				blocks   [][]int
+				returns  []int
+				calls    map[*ast.CallExpr]int
+				deferred map[*ast.CallExpr]*ast.CallExpr
+			)
+
+			if fn.Body != nil {
+				blocks, returns, calls = flow(info, fn.Body)
+				deferred = deferredCalls(fn.Body)
+			}
+
+			decls[name] = FuncDecl{
+				Name:     name,
+				Package:  pkgName,
+				Position: position.String(),
+				End:      fset.Position(fn.End()).String(),
+				Calls:    []FuncCall{},
+				Blocks:   blocks,
+				Returns:  returns,
+				Test:     strings.HasSuffix(position.Filename, "_test.go"),
+
+				Deprecated: deprecation(fn.Doc),
+			}
+
+			if key, iface := method(info, fn.Name); !iface {
+				decl := decls[name]
+				decl.Method = key
+				decls[name] = decl
+			}
+
+			vis := funcDeclVisitor{
+				info,
+				fset,
+				name,
+				decls,
+				calls,
+				deferred,
+				make(map[*ast.Ident]struct{}),
+				false,
+			}
+
+			// Walk contents of the function declaration
+			ast.Walk(&vis, fn)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkInitial(t *testing.T) {
+	decls := map[string]FuncDecl{
+		"a.main":  {Name: "a.main", Package: "a"},
+		"a/b.Run": {Name: "a/b.Run", Package: "a/b"},
+		"c.New":   {Name: "c.New", Package: "c", Initial: true},
+		"panic":   {Name: "panic"},
+	}
+
+	MarkInitial(decls, map[string]struct{}{"a": {}, "a/b": {}})
+
+	initial := make(map[string]bool)
+	for name, decl := range decls {
+		initial[name] = decl.Initial
+	}
+
+	assert.Equal(t, map[string]bool{
+		"a.main":  true,
+		"a/b.Run": true,
+		"c.New":   false,
+		"panic":   false,
+	}, initial)
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"sort"
+)
+
+// Callers returns a reverse index of the given call graph, mapping the name of
+// every called function to each of its call sites. The Name and Package of
+// each returned FuncCall describe the calling function, while the Position is
+// that of the call site.
+func Callers(decls map[string]FuncDecl) map[string][]FuncCall {
+	callers := make(map[string][]FuncCall)
+
+	for name, decl := range decls {
+		for _, call := range decl.Calls {
+			callers[call.Name] = append(callers[call.Name], FuncCall{
+				Name:     name,
+				Package:  decl.Package,
+				Position: call.Position,
+			})
+		}
+	}
+
+	// Sort call sites so that the index is deterministic.
+	for _, sites := range callers {
+		sort.Slice(sites, func(i, j int) bool {
+			if sites[i].Name != sites[j].Name {
+				return sites[i].Name < sites[j].Name
+			}
+			return LessPosition(sites[i].Position, sites[j].Position)
+		})
+	}
+
+	return callers
+}
+
+// Callees returns a forward index of the given call graph, mapping the name of
+// every declared function to each of its call sites.
+func Callees(decls map[string]FuncDecl) map[string][]FuncCall {
+	callees := make(map[string][]FuncCall, len(decls))
+	for name, decl := range decls {
+		callees[name] = decl.Calls
+	}
+	return callees
+}
+
+// Reaching returns the name of every function from which the function named
+// end can be reached, including end itself.
+func Reaching(callers map[string][]FuncCall, end string) map[string]struct{} {
+	var (
+		reaching = map[string]struct{}{end: {}}
+		queue    = []string{end}
+	)
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, caller := range callers[current] {
+			if _, found := reaching[caller.Name]; !found {
+				reaching[caller.Name] = struct{}{}
+				queue = append(queue, caller.Name)
+			}
+		}
+	}
+
+	return reaching
+}
@@ -0,0 +1,84 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallers(t *testing.T) {
+	cg := map[string]FuncDecl{
+		"main": {
+			Name:    "main",
+			Package: "a",
+			Calls: []FuncCall{
+				{Name: "run", Position: "main.go:2:1"},
+				{Name: "exit", Position: "main.go:3:1"},
+			},
+		},
+		"run": {
+			Name:    "run",
+			Package: "a",
+			Calls: []FuncCall{
+				{Name: "exit", Position: "run.go:2:1"},
+			},
+		},
+		"other": {
+			Name:    "other",
+			Package: "b",
+			Calls: []FuncCall{
+				{Name: "run", Position: "other.go:2:1"},
+			},
+		},
+		"unrelated": {
+			Name: "unrelated",
+		},
+	}
+
+	callers := Callers(cg)
+
+	assert.Equal(t, map[string][]FuncCall{
+		"run": {
+			{Name: "main", Package: "a", Position: "main.go:2:1"},
+			{Name: "other", Package: "b", Position: "other.go:2:1"},
+		},
+		"exit": {
+			{Name: "main", Package: "a", Position: "main.go:3:1"},
+			{Name: "run", Package: "a", Position: "run.go:2:1"},
+		},
+	}, callers)
+
+	assert.Equal(t, map[string]struct{}{
+		"exit":  {},
+		"main":  {},
+		"run":   {},
+		"other": {},
+	}, Reaching(callers, "exit"))
+}
+
+func TestCallees(t *testing.T) {
+	cg := map[string]FuncDecl{
+		"main": {
+			Name: "main",
+			Calls: []FuncCall{
+				{Name: "run", Position: "main.go:2:1"},
+				{Name: "exit", Position: "main.go:3:1"},
+			},
+		},
+		"unrelated": {
+			Name: "unrelated",
+		},
+	}
+
+	assert.Equal(t, map[string][]FuncCall{
+		"main": {
+			{Name: "run", Position: "main.go:2:1"},
+			{Name: "exit", Position: "main.go:3:1"},
+		},
+		"unrelated": nil,
+	}, Callees(cg))
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+// Merge adds every function and call in src to dst, labelling each with the
+// named build configuration. Functions and calls that already exist in dst
+// have the configuration appended to their labels instead. Calls are kept in
+// the order in which they appear in the source of their caller.
+func Merge(dst map[string]FuncDecl, src map[string]FuncDecl, config string) {
+	for name, decl := range src {
+		existing, found := dst[name]
+		if !found {
+			existing = FuncDecl{
+				Name:     decl.Name,
+				Package:  decl.Package,
+				Position: decl.Position,
+				End:      decl.End,
+				Calls:    []FuncCall{},
+				Blocks:   decl.Blocks,
+				Returns:  decl.Returns,
+				Test:     decl.Test,
+				Method:   decl.Method,
+
+				Deprecated: decl.Deprecated,
+			}
+		} else if existing.Position != decl.Position || existing.End != decl.End {
+			// The function is declared elsewhere in this configuration, so
+			// its calls cannot be ordered against those of the other
+			// declaration.
+			existing.Alternates = mergeDeclaration(existing.Alternates, decl, config)
+			existing.Blocks = nil
+			existing.Returns = nil
+		} else if !sameBlocks(existing.Blocks, decl.Blocks) {
+			// The control flow of the function differs between
+			// configurations, so the blocks of its calls cannot be
+			// compared.
+			existing.Blocks = nil
+			existing.Returns = nil
+		}
+
+		existing.Initial = existing.Initial || decl.Initial
+		existing.Configs = append(existing.Configs, config)
+
+		for _, call := range decl.Calls {
+			existing.Calls = mergeCall(existing.Calls, call, config)
+		}
+
+		for _, reference := range decl.References {
+			existing.References = mergeReference(existing.References, reference)
+		}
+
+		dst[name] = existing
+	}
+}
+
+func mergeCall(calls []FuncCall, call FuncCall, config string) []FuncCall {
+	for index, existing := range calls {
+		if existing.Name == call.Name && existing.Position == call.Position {
+			calls[index].Configs = append(calls[index].Configs, config)
+			return calls
+		}
+	}
+
+	call.Configs = []string{config}
+
+	// Insert the call before the first call that appears after it, so that
+	// the index of every call still follows the source of its caller.
+	index := len(calls)
+	for index > 0 && LessPosition(call.Position, calls[index-1].Position) {
+		index--
+	}
+
+	calls = append(calls, FuncCall{})
+	copy(calls[index+1:], calls[index:])
+	calls[index] = call
+
+	return calls
+}
+
+func mergeReference(references []string, reference string) []string {
+	for _, existing := range references {
+		if existing == reference {
+			return references
+		}
+	}
+
+	return append(references, reference)
+}
+
+func mergeDeclaration(alternates []Declaration, decl FuncDecl, config string) []Declaration {
+	for index, existing := range alternates {
+		if existing.Position == decl.Position && existing.End == decl.End {
+			alternates[index].Configs = append(alternates[index].Configs, config)
+			return alternates
+		}
+	}
+
+	return append(alternates, Declaration{
+		Position: decl.Position,
+		End:      decl.End,
+		Configs:  []string{config},
+	})
+}
+
+func sameBlocks(a [][]int, b [][]int) bool {
+	if len(a) != len(b) || (a == nil) != (b == nil) {
+		return false
+	}
+
+	for index := range a {
+		if len(a[index]) != len(b[index]) {
+			return false
+		}
+		for succ := range a[index] {
+			if a[index][succ] != b[index][succ] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	linux := map[string]FuncDecl{
+		"main": {
+			Name:     "main",
+			Position: "main.go:1:1",
+			Calls: []FuncCall{
+				{Name: "run", Position: "main.go:2:2"},
+				{Name: "quit", Position: "main.go:3:2"},
+			},
+		},
+		"quit": {
+			Name:     "quit",
+			Position: "quit_linux.go:1:1",
+			Blocks:   [][]int{{}},
+		},
+	}
+
+	windows := map[string]FuncDecl{
+		"main": {
+			Name:     "main",
+			Position: "main.go:1:1",
+			Calls: []FuncCall{
+				{Name: "run", Position: "main.go:2:2"},
+				{Name: "log", Position: "main.go:2:9"},
+				{Name: "quit", Position: "main.go:3:2"},
+			},
+		},
+		"quit": {
+			Name:     "quit",
+			Position: "quit_windows.go:1:1",
+			Blocks:   [][]int{{}},
+			Calls: []FuncCall{
+				{Name: "exit", Position: "quit_windows.go:2:2"},
+			},
+		},
+	}
+
+	merged := make(map[string]FuncDecl)
+	Merge(merged, linux, "linux")
+	Merge(merged, windows, "windows")
+
+	assert.Equal(t, map[string]FuncDecl{
+		"main": {
+			Name:     "main",
+			Position: "main.go:1:1",
+			Calls: []FuncCall{
+				{Name: "run", Position: "main.go:2:2", Configs: []string{"linux", "windows"}},
+				{Name: "log", Position: "main.go:2:9", Configs: []string{"windows"}},
+				{Name: "quit", Position: "main.go:3:2", Configs: []string{"linux", "windows"}},
+			},
+			Configs: []string{"linux", "windows"},
+		},
+		"quit": {
+			Name:     "quit",
+			Position: "quit_linux.go:1:1",
+			Calls: []FuncCall{
+				{Name: "exit", Position: "quit_windows.go:2:2", Configs: []string{"windows"}},
+			},
+			Configs: []string{"linux", "windows"},
+			Alternates: []Declaration{
+				{Position: "quit_windows.go:1:1", Configs: []string{"windows"}},
+			},
+		},
+	}, merged)
+}
@@ -0,0 +1,313 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Metrics measures how a single function is connected to the rest of a call
+// graph.
+type Metrics struct {
+	Name     string `json:"name"`
+	Package  string `json:"package"`
+	Position string `json:"position"`
+
+	// FanIn is the number of distinct functions that call this function, and
+	// FanOut is the number of distinct functions that it calls.
+	FanIn  int `json:"fan_in"`
+	FanOut int `json:"fan_out"`
+
+	// TransitiveFanIn is the number of distinct functions that can reach
+	// this function through any chain of calls, and TransitiveFanOut is the
+	// number of distinct functions that it can reach.
+	TransitiveFanIn  int `json:"transitive_fan_in"`
+	TransitiveFanOut int `json:"transitive_fan_out"`
+
+	// Depth is the number of calls in the longest chain of calls starting
+	// at this function. Calls between mutually recursive functions are not
+	// counted, so that the depth is always finite.
+	Depth int `json:"depth"`
+}
+
+// PackageMetrics aggregates the metrics of every function in a package.
+type PackageMetrics struct {
+	Package   string `json:"package"`
+	Functions int    `json:"functions"`
+
+	// FanIn is the number of distinct functions outside of the package that
+	// call into it, and FanOut is the number of distinct functions outside
+	// of the package that it calls.
+	FanIn  int `json:"fan_in"`
+	FanOut int `json:"fan_out"`
+
+	// The remaining fields are the largest of each metric among the
+	// functions in the package.
+	MaxTransitiveFanIn  int `json:"max_transitive_fan_in"`
+	MaxTransitiveFanOut int `json:"max_transitive_fan_out"`
+	MaxDepth            int `json:"max_depth"`
+}
+
+// Measure returns the metrics of every named function in the given call
+// graph, in the order that they were named.
+func Measure(decls map[string]FuncDecl, names []string) []Metrics {
+	var (
+		callers = Callers(decls)
+		depths  = depths(decls)
+		results = make([]Metrics, 0, len(names))
+	)
+
+	callees := func(name string) []string {
+		var names []string
+		for _, call := range decls[name].Calls {
+			names = append(names, call.Name)
+		}
+		return names
+	}
+
+	callerNames := func(name string) []string {
+		var names []string
+		for _, caller := range callers[name] {
+			names = append(names, caller.Name)
+		}
+		return names
+	}
+
+	var (
+		components = components(decls, true)
+		reversed   = make([][]string, len(components))
+	)
+
+	// Components are ordered after their callees, and so in reverse after
+	// their callers.
+	for index, members := range components {
+		reversed[len(components)-1-index] = members
+	}
+
+	var (
+		transitiveFanOut = closure(components, callees)
+		transitiveFanIn  = closure(reversed, callerNames)
+	)
+
+	for _, name := range names {
+		decl := decls[name]
+
+		results = append(results, Metrics{
+			Name:             name,
+			Package:          decl.Package,
+			Position:         decl.Position,
+			FanIn:            len(distinct(callerNames(name))),
+			FanOut:           len(distinct(callees(name))),
+			TransitiveFanIn:  transitiveFanIn(name),
+			TransitiveFanOut: transitiveFanOut(name),
+			Depth:            depths[name],
+		})
+	}
+
+	return results
+}
+
+// Aggregate combines the given function metrics into metrics for each of the
+// packages that the functions belong to, sorted by package.
+func Aggregate(decls map[string]FuncDecl, metrics []Metrics) []PackageMetrics {
+	var (
+		packages = make(map[string]*PackageMetrics)
+		fanIn    = make(map[string]map[string]struct{})
+		fanOut   = make(map[string]map[string]struct{})
+	)
+
+	for _, metric := range metrics {
+		aggregate, found := packages[metric.Package]
+		if !found {
+			aggregate = &PackageMetrics{Package: metric.Package}
+			packages[metric.Package] = aggregate
+			fanIn[metric.Package] = make(map[string]struct{})
+			fanOut[metric.Package] = make(map[string]struct{})
+		}
+
+		aggregate.Functions++
+		aggregate.MaxTransitiveFanIn = max(aggregate.MaxTransitiveFanIn, metric.TransitiveFanIn)
+		aggregate.MaxTransitiveFanOut = max(aggregate.MaxTransitiveFanOut, metric.TransitiveFanOut)
+		aggregate.MaxDepth = max(aggregate.MaxDepth, metric.Depth)
+
+		for _, call := range decls[metric.Name].Calls {
+			if call.Package != metric.Package {
+				fanOut[metric.Package][call.Name] = struct{}{}
+			}
+		}
+	}
+
+	for name, decl := range decls {
+		for _, call := range decl.Calls {
+			if callee, found := decls[call.Name]; found && callee.Package != decl.Package && fanIn[callee.Package] != nil {
+				fanIn[callee.Package][name] = struct{}{}
+			}
+		}
+	}
+
+	results := make([]PackageMetrics, 0, len(packages))
+
+	for pkg, aggregate := range packages {
+		aggregate.FanIn = len(fanIn[pkg])
+		aggregate.FanOut = len(fanOut[pkg])
+		results = append(results, *aggregate)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Package < results[j].Package
+	})
+
+	return results
+}
+
+// depths returns the depth of every function in the given call graph. As
+// components are returned after every component that they call, the depth of
+// each callee is always known before that of its callers.
+func depths(decls map[string]FuncDecl) map[string]int {
+	var (
+		depths    = make(map[string]int, len(decls))
+		component = make(map[string]int, len(decls))
+	)
+
+	for index, members := range components(decls, true) {
+		for _, name := range members {
+			component[name] = index
+		}
+
+		depth := 0
+
+		for _, name := range members {
+			for _, call := range decls[name].Calls {
+				callee, declared := component[call.Name]
+
+				switch {
+				case !declared:
+					// Calls to undeclared functions, such as builtins,
+					// are leaves.
+					depth = max(depth, 1)
+				case callee != index:
+					depth = max(depth, depths[call.Name]+1)
+				}
+			}
+		}
+
+		for _, name := range members {
+			depths[name] = depth
+		}
+	}
+
+	return depths
+}
+
+// closure returns a function that counts the distinct functions, other than
+// the given function itself, that can be reached from it by repeatedly
+// following the given edges. Every component must come after all of the
+// components that its edges lead to, so that the functions reachable from
+// each component are found once, from those of the components it leads to.
+func closure(components [][]string, edges func(string) []string) func(string) int {
+	var (
+		index     = make(map[string]int)
+		component = make(map[string]int)
+		reachable = make([]bitset, len(components))
+	)
+
+	// Functions outside of every component, such as builtins, are indexed
+	// as they are found.
+	indexOf := func(name string) int {
+		if position, found := index[name]; found {
+			return position
+		}
+		index[name] = len(index)
+		return index[name]
+	}
+
+	for position, members := range components {
+		for _, name := range members {
+			indexOf(name)
+			component[name] = position
+		}
+	}
+
+	// follow returns the functions reachable from the given function by one
+	// or more edges, given those of every component that it leads to.
+	follow := func(name string, self int) bitset {
+		var set bitset
+		for _, next := range edges(name) {
+			set.add(indexOf(next))
+
+			if position, found := component[next]; found {
+				if position == self {
+					// Every member of a cycle reaches every other.
+					for _, member := range components[self] {
+						set.add(index[member])
+					}
+				} else {
+					set.union(reachable[position])
+				}
+			}
+		}
+		return set
+	}
+
+	for position, members := range components {
+		for _, name := range members {
+			reachable[position].union(follow(name, position))
+		}
+	}
+
+	return func(name string) int {
+		position, found := component[name]
+		if !found {
+			return follow(name, -1).count()
+		}
+
+		count := reachable[position].count()
+		if reachable[position].has(index[name]) {
+			count--
+		}
+		return count
+	}
+}
+
+// bitset is a set of small non-negative integers.
+type bitset []uint64
+
+func (set *bitset) add(value int) {
+	for len(*set) <= value/64 {
+		*set = append(*set, 0)
+	}
+	(*set)[value/64] |= 1 << uint(value%64)
+}
+
+func (set bitset) has(value int) bool {
+	return value/64 < len(set) && set[value/64]&(1<<uint(value%64)) != 0
+}
+
+func (set *bitset) union(other bitset) {
+	for len(*set) < len(other) {
+		*set = append(*set, 0)
+	}
+	for index, word := range other {
+		(*set)[index] |= word
+	}
+}
+
+func (set bitset) count() int {
+	count := 0
+	for _, word := range set {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+// distinct returns the given names with duplicates removed.
+func distinct(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[name] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	cg := map[string]FuncDecl{
+		"a.main": {
+			Name:    "a.main",
+			Package: "a",
+			Calls: []FuncCall{
+				{Name: "a.run", Package: "a"},
+				{Name: "a.run", Package: "a"},
+				{Name: "b.even", Package: "b"},
+			},
+		},
+		"a.run": {
+			Name:    "a.run",
+			Package: "a",
+			Calls: []FuncCall{
+				{Name: "b.even", Package: "b"},
+			},
+		},
+		"b.even": {
+			Name:    "b.even",
+			Package: "b",
+			Calls: []FuncCall{
+				{Name: "b.odd", Package: "b"},
+			},
+		},
+		"b.odd": {
+			Name:    "b.odd",
+			Package: "b",
+			Calls: []FuncCall{
+				{Name: "b.even", Package: "b"},
+				{Name: "panic"},
+			},
+		},
+	}
+
+	metrics := Measure(cg, []string{"a.main", "a.run", "b.even", "b.odd"})
+
+	assert.Equal(t, []Metrics{
+		{Name: "a.main", Package: "a", FanIn: 0, FanOut: 2, TransitiveFanIn: 0, TransitiveFanOut: 4, Depth: 3},
+		{Name: "a.run", Package: "a", FanIn: 1, FanOut: 1, TransitiveFanIn: 1, TransitiveFanOut: 3, Depth: 2},
+		{Name: "b.even", Package: "b", FanIn: 3, FanOut: 1, TransitiveFanIn: 3, TransitiveFanOut: 2, Depth: 1},
+		{Name: "b.odd", Package: "b", FanIn: 1, FanOut: 2, TransitiveFanIn: 3, TransitiveFanOut: 2, Depth: 1},
+	}, metrics)
+
+	assert.Equal(t, []PackageMetrics{
+		{Package: "a", Functions: 2, FanIn: 0, FanOut: 1, MaxTransitiveFanIn: 1, MaxTransitiveFanOut: 4, MaxDepth: 3},
+		{Package: "b", Functions: 2, FanIn: 2, FanOut: 1, MaxTransitiveFanIn: 3, MaxTransitiveFanOut: 2, MaxDepth: 1},
+	}, Aggregate(cg, metrics))
+}
+
+func TestMetricsRecursion(t *testing.T) {
+	cg := map[string]FuncDecl{
+		"c.top": {
+			Name:    "c.top",
+			Package: "c",
+			Calls: []FuncCall{
+				{Name: "c.loop", Package: "c"},
+			},
+		},
+		"c.loop": {
+			Name:    "c.loop",
+			Package: "c",
+			Calls: []FuncCall{
+				{Name: "c.loop", Package: "c"},
+				{Name: "c.leaf", Package: "c"},
+			},
+		},
+		"c.leaf": {
+			Name:    "c.leaf",
+			Package: "c",
+			Calls: []FuncCall{
+				{Name: "os.Exit", Package: "os"},
+			},
+		},
+	}
+
+	assert.Equal(t, []Metrics{
+		{Name: "c.top", Package: "c", FanIn: 0, FanOut: 1, TransitiveFanIn: 0, TransitiveFanOut: 3, Depth: 3},
+		{Name: "c.loop", Package: "c", FanIn: 2, FanOut: 2, TransitiveFanIn: 1, TransitiveFanOut: 2, Depth: 2},
+		{Name: "c.leaf", Package: "c", FanIn: 1, FanOut: 1, TransitiveFanIn: 2, TransitiveFanOut: 1, Depth: 1},
+		{Name: "os.Exit", FanIn: 1, FanOut: 0, TransitiveFanIn: 3, TransitiveFanOut: 0, Depth: 0},
+	}, Measure(cg, []string{"c.top", "c.loop", "c.leaf", "os.Exit"}))
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"go/build"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Pattern matches functions either by their fully qualified name, or by the
+// import path of the package that they belong to.
+//
+// When matching names, "*" matches any sequence of characters, and "?"
+// matches any single character. When matching import paths, "..." matches
+// any sequence of characters, as with the go tool, so that "net/..." matches
+// both "net" and "net/http". When matching file paths, the pattern may match
+// any trailing sequence of path elements, so that "internal/api/..." matches
+// every file beneath any internal/api directory, and "*_gen.go" matches every
+// file whose name ends in _gen.go.
+//
+// As with the go tool, the pattern "std" matches the standard library, which
+// is every file beneath the src directory of GOROOT.
+type Pattern struct {
+	name *regexp.Regexp
+	pkg  *regexp.Regexp
+	file *regexp.Regexp
+}
+
+// stdPattern is the pattern that matches the standard library.
+const stdPattern = "std"
+
+// never is a regular expression that matches nothing.
+var never = regexp.MustCompile(`^\z.`)
+
+// patterns memoizes compiled patterns, as the same pattern is often matched
+// against every function in a call graph.
+var patterns sync.Map
+
+func NewPattern(pattern string) Pattern {
+	if compiled, found := patterns.Load(pattern); found {
+		return compiled.(Pattern)
+	}
+
+	compiled := Pattern{
+		name: globRegex(pattern),
+		pkg:  packageRegex(pattern),
+		file: fileRegex(pattern),
+	}
+
+	if pattern == stdPattern {
+		compiled = Pattern{
+			name: never,
+			pkg:  never,
+			file: stdRegex(build.Default.GOROOT),
+		}
+	}
+
+	patterns.Store(pattern, compiled)
+
+	return compiled
+}
+
+// MatchName reports whether the given fully qualified function name matches
+// the pattern.
+func (p Pattern) MatchName(name string) bool {
+	return p.name.MatchString(name)
+}
+
+// MatchPackage reports whether the given import path matches the pattern.
+func (p Pattern) MatchPackage(pkg string) bool {
+	return p.pkg.MatchString(pkg)
+}
+
+// MatchFile reports whether the given file path, or the directory containing
+// it, matches the pattern.
+func (p Pattern) MatchFile(filename string) bool {
+	filename = filepath.ToSlash(filename)
+	return p.file.MatchString(filename) || p.file.MatchString(path.Dir(filename))
+}
+
+// Match reports whether the given function matches the pattern, either by
+// name or by package.
+func (p Pattern) Match(name string, pkg string) bool {
+	return p.MatchName(name) || (pkg != "" && p.MatchPackage(pkg))
+}
+
+// IsGlob reports whether the given pattern contains any wildcards. The "*" of
+// a pointer receiver, as in "(*sync.Mutex).Lock", is not a wildcard.
+func IsGlob(pattern string) bool {
+	pattern = strings.Replace(pattern, "(*", "(", -1)
+	return strings.ContainsAny(pattern, "*?") || strings.Contains(pattern, "...")
+}
+
+// Expand returns the names of every function in the given graph that match
+// pattern. Names without wildcards are returned unchanged. When calls is
+// true, functions that are only called, and never declared, are considered
+// as well.
+func Expand(pattern string, decls map[string]FuncDecl, calls bool) []string {
+	if !IsGlob(pattern) {
+		return []string{pattern}
+	}
+
+	var (
+		matcher = NewPattern(pattern)
+		matched = make(map[string]struct{})
+	)
+
+	for name, decl := range decls {
+		if matcher.MatchName(name) {
+			matched[name] = struct{}{}
+		}
+
+		if !calls {
+			continue
+		}
+
+		for _, call := range decl.Calls {
+			if matcher.MatchName(call.Name) {
+				matched[call.Name] = struct{}{}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(matched))
+	for name := range matched {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func globRegex(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "(*")
+	for index, part := range parts {
+		expr := regexp.QuoteMeta(part)
+		expr = strings.Replace(expr, `\*`, `.*`, -1)
+		expr = strings.Replace(expr, `\?`, `.`, -1)
+		parts[index] = expr
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, `\(\*`) + "$")
+}
+
+func packageRegex(pattern string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(pattern)
+	if strings.HasSuffix(expr, `/\.\.\.`) {
+		expr = strings.TrimSuffix(expr, `/\.\.\.`) + `(/\.\.\.)?`
+	}
+	expr = strings.Replace(expr, `\.\.\.`, `.*`, -1)
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+func stdRegex(goroot string) *regexp.Regexp {
+	return regexp.MustCompile("^" + regexp.QuoteMeta(filepath.ToSlash(filepath.Join(goroot, "src"))) + "/")
+}
+
+func fileRegex(pattern string) *regexp.Regexp {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	expr := regexp.QuoteMeta(pattern)
+	if strings.HasSuffix(expr, `/\.\.\.`) {
+		expr = strings.TrimSuffix(expr, `/\.\.\.`) + `(/\.\.\.)?`
+	}
+	expr = strings.Replace(expr, `\.\.\.`, `.*`, -1)
+	expr = strings.Replace(expr, `\*`, `[^/]*`, -1)
+	expr = strings.Replace(expr, `\?`, `[^/]`, -1)
+	if strings.HasPrefix(pattern, "/") {
+		return regexp.MustCompile("^" + expr + "$")
+	}
+	return regexp.MustCompile("(^|/)" + expr + "$")
+}
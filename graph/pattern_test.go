@@ -0,0 +1,135 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPattern(t *testing.T) {
+
+	tests := []struct {
+		pattern string
+		name    string
+		pkg     string
+		matches bool
+	}{
+		{pattern: "os.Exit", name: "os.Exit", pkg: "os", matches: true},
+		{pattern: "os.Exit", name: "os.Exited", pkg: "os", matches: false},
+		{pattern: "os.*", name: "os.Getenv", pkg: "os", matches: true},
+		{pattern: "os.Ex?t", name: "os.Exit", pkg: "os", matches: true},
+		{pattern: "(*sync.*).Lock", name: "(*sync.RWMutex).Lock", pkg: "sync", matches: true},
+		{pattern: "(*sync.*).Lock", name: "(sync.Locker).Lock", pkg: "sync", matches: false},
+		{pattern: "(*sync.Mutex).Lock", name: "(*sync.Mutex).Lock", pkg: "sync", matches: true},
+		{pattern: "net/...", name: "net/http.Get", pkg: "net/http", matches: true},
+		{pattern: "net/...", name: "net.Dial", pkg: "net", matches: true},
+		{pattern: "net/...", name: "netip.Addr", pkg: "netip", matches: false},
+		{pattern: "internal/api/...", name: "a/internal/api.Serve", pkg: "a/internal/api", matches: false},
+		{pattern: ".../internal/api/...", name: "a/internal/api.Serve", pkg: "a/internal/api", matches: true},
+		{pattern: "panic", name: "panic", matches: true},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s matches %s", index, test.pattern, test.name)
+
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.matches, NewPattern(test.pattern).Match(test.name, test.pkg))
+		})
+	}
+}
+
+func TestPatternMatchFile(t *testing.T) {
+
+	tests := []struct {
+		pattern  string
+		filename string
+		matches  bool
+	}{
+		{pattern: "internal/api/...", filename: "/src/a/internal/api/serve.go", matches: true},
+		{pattern: "internal/api/...", filename: "/src/a/internal/api/v1/serve.go", matches: true},
+		{pattern: "internal/api/...", filename: "/src/a/internal/apis/serve.go", matches: false},
+		{pattern: "internal/api", filename: "/src/a/internal/api/serve.go", matches: true},
+		{pattern: "internal/api", filename: "/src/a/internal/api/v1/serve.go", matches: false},
+		{pattern: "./cmd/...", filename: "/src/a/cmd/main.go", matches: true},
+		{pattern: "/src/a/...", filename: "/src/a/main.go", matches: true},
+		{pattern: "/a/...", filename: "/src/a/main.go", matches: false},
+		{pattern: "*_gen.go", filename: "/src/a/types_gen.go", matches: true},
+		{pattern: "*_gen.go", filename: "/src/a/types.go", matches: false},
+		{pattern: "a/*.go", filename: "/src/a/b/types.go", matches: false},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s matches %s", index, test.pattern, test.filename)
+
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.matches, NewPattern(test.pattern).MatchFile(test.filename))
+		})
+	}
+}
+
+func TestPatternStd(t *testing.T) {
+	std := NewPattern("std")
+	goroot := filepath.ToSlash(build.Default.GOROOT)
+
+	assert.True(t, std.MatchFile(goroot+"/src/fmt/print.go"))
+	assert.True(t, std.MatchFile(goroot+"/src/net/http/server.go"))
+	assert.False(t, std.MatchFile("/src/a/std/main.go"))
+	assert.False(t, std.MatchPackage("fmt"))
+	assert.False(t, std.MatchName("std"))
+}
+
+func TestIsGlob(t *testing.T) {
+	assert.False(t, IsGlob("os.Exit"))
+	assert.False(t, IsGlob("(*sync.Mutex).Lock"))
+	assert.True(t, IsGlob("(*sync.*).Lock"))
+	assert.True(t, IsGlob("os.*"))
+	assert.True(t, IsGlob("net/..."))
+}
+
+func TestExpand(t *testing.T) {
+
+	decls := map[string]FuncDecl{
+		"main.main": {
+			Name: "main.main",
+			Calls: []FuncCall{
+				{Name: "main.run"},
+				{Name: "os.Exit"},
+			},
+		},
+		"main.run": {
+			Name: "main.run",
+			Calls: []FuncCall{
+				{Name: "os.Getenv"},
+			},
+		},
+	}
+
+	tests := []struct {
+		pattern  string
+		calls    bool
+		expected []string
+	}{
+		{pattern: "main.main", expected: []string{"main.main"}},
+		{pattern: "main.missing", expected: []string{"main.missing"}},
+		{pattern: "main.*", expected: []string{"main.main", "main.run"}},
+		{pattern: "main.*", calls: true, expected: []string{"main.main", "main.run"}},
+		{pattern: "os.*", expected: []string{}},
+		{pattern: "os.*", calls: true, expected: []string{"os.Exit", "os.Getenv"}},
+		{pattern: "*", calls: true, expected: []string{"main.main", "main.run", "os.Exit", "os.Getenv"}},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s calls=%t", index, test.pattern, test.calls)
+
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Expand(test.pattern, decls, test.calls))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var positionRegex = regexp.MustCompile(`^(.*):(\d+):(\d+)$`)
+
+// ParsePosition splits a position, as recorded in FuncDecl and FuncCall, into
+// its filename, line, and column. The returned bool is false if the position
+// could not be parsed.
+func ParsePosition(position string) (string, int, int, bool) {
+	matches := positionRegex.FindStringSubmatch(position)
+	if matches == nil {
+		return "", 0, 0, false
+	}
+
+	line, _ := strconv.Atoi(matches[2])
+	column, _ := strconv.Atoi(matches[3])
+
+	return matches[1], line, column, true
+}
+
+// LessPosition reports whether position a sorts before position b, comparing
+// lines and columns numerically rather than lexically.
+func LessPosition(a string, b string) bool {
+	fileA, lineA, colA, okA := ParsePosition(a)
+	fileB, lineB, colB, okB := ParsePosition(b)
+
+	switch {
+	case !okA || !okB:
+		return a < b
+	case fileA != fileB:
+		return fileA < fileB
+	case lineA != lineB:
+		return lineA < lineB
+	default:
+		return colA < colB
+	}
+}
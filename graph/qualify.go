@@ -6,12 +6,17 @@ package graph
 
 import (
 	"go/ast"
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/loader"
 )
 
 func Qualify(pkg *loader.PackageInfo, node ast.Node) (string, string, bool) {
+	return qualify(&pkg.Info, node)
+}
+
+func qualify(info *types.Info, node ast.Node) (string, string, bool) {
 	var ident *ast.Ident
 
 	switch kind := node.(type) {
@@ -29,7 +34,7 @@ func Qualify(pkg *loader.PackageInfo, node ast.Node) (string, string, bool) {
 		}
 	}
 
-	switch fn := pkg.ObjectOf(ident).(type) {
+	switch fn := info.ObjectOf(ident).(type) {
 	case *types.Func:
 		// Builtin interface function call like err.Error()
 		if fn.Pkg() == nil {
@@ -42,3 +47,35 @@ func Qualify(pkg *loader.PackageInfo, node ast.Node) (string, string, bool) {
 		return "", "", false
 	}
 }
+
+// method returns the name and signature of the method named by the given
+// identifier, which identify the methods that a call through an interface may
+// be dispatched to. The second result reports whether the method belongs to
+// an interface. The first result is empty if the identifier does not name a
+// method.
+func method(info *types.Info, ident *ast.Ident) (string, bool) {
+	fn, ok := info.ObjectOf(ident).(*types.Func)
+	if !ok {
+		return "", false
+	}
+
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return "", false
+	}
+
+	// The signature of a method is written without its receiver, or the
+	// names of its parameters and results.
+	bare := types.NewSignatureType(nil, nil, nil, unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic())
+
+	return fn.Name() + " " + types.TypeString(bare, nil), types.IsInterface(sig.Recv().Type())
+}
+
+// unnamed returns the given tuple without the names of its variables.
+func unnamed(tuple *types.Tuple) *types.Tuple {
+	vars := make([]*types.Var, tuple.Len())
+	for index := range vars {
+		vars[index] = types.NewVar(token.NoPos, nil, "", tuple.At(index).Type())
+	}
+	return types.NewTuple(vars...)
+}
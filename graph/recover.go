@@ -0,0 +1,40 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+// Recovers returns the name of every function that defers a call to recover,
+// and so stops any panic that passes through it. As recover only stops a panic
+// when called directly by a deferred function, it must be called from a
+// deferred function literal, or from a declared function that is itself
+// deferred. Deferring recover itself has no effect.
+func Recovers(decls map[string]FuncDecl) map[string]struct{} {
+	recovers := make(map[string]struct{})
+
+	for name, decl := range decls {
+		for _, call := range decl.Calls {
+			if !call.Deferred {
+				continue
+			}
+
+			if call.Literal && call.Name == "recover" || !call.Literal && callsRecover(decls[call.Name]) {
+				recovers[name] = struct{}{}
+				break
+			}
+		}
+	}
+
+	return recovers
+}
+
+// callsRecover reports whether the given function calls recover directly,
+// which is only effective when the function is itself deferred.
+func callsRecover(decl FuncDecl) bool {
+	for _, call := range decl.Calls {
+		if call.Name == "recover" && !call.Deferred && !call.Literal {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const recoverSource = `package p
+
+func guard() {
+	recover()
+}
+
+func indirect() {
+	guard()
+}
+
+func literal() {
+	defer func() {
+		recover()
+	}()
+}
+
+func declared() {
+	defer guard()
+}
+
+func bare() {
+	defer recover()
+}
+
+func nested() {
+	defer func() {
+		func() {
+			recover()
+		}()
+	}()
+}
+
+func wrapped() {
+	defer func() {
+		guard()
+	}()
+}
+
+func deferredIndirect() {
+	defer indirect()
+}
+
+func goroutine() {
+	go func() {
+		defer func() {
+			recover()
+		}()
+	}()
+	panic("boom")
+}
+`
+
+func TestRecovers(t *testing.T) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "p.go", recoverSource, 0)
+	require.NoError(t, err)
+
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+
+	config := types.Config{Importer: importer.Default()}
+	_, err = config.Check("p", fset, []*ast.File{file}, info)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]struct{}{
+		"p.literal":  {},
+		"p.declared": {},
+	}, Recovers(Package(fset, info, []*ast.File{file})))
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// testPrefixes lists the name prefixes of functions that are run by go test.
+var testPrefixes = []string{"Test", "Benchmark", "Example", "Fuzz"}
+
+// Entrypoints returns the name of every function that may be called from
+// outside of the given call graph. These are main and init functions, test
+// functions, and the exported functions and methods of every package that is
+// not a command.
+func Entrypoints(decls map[string]FuncDecl) map[string]struct{} {
+	commands := make(map[string]struct{})
+	for _, decl := range decls {
+		if decl.Name == decl.Package+".main" {
+			commands[decl.Package] = struct{}{}
+		}
+	}
+
+	entrypoints := make(map[string]struct{})
+
+	for name, decl := range decls {
+		_, command := commands[decl.Package]
+
+		switch {
+		case name == decl.Package+".main", name == decl.Package+".init":
+		case decl.Test && hasTestPrefix(unqualified(name)):
+		case !command && IsExported(name):
+		default:
+			continue
+		}
+
+		entrypoints[name] = struct{}{}
+	}
+
+	return entrypoints
+}
+
+// Unreachable returns the sorted names of the functions, declared in the
+// packages that the program was loaded from, that cannot be reached by any
+// chain of calls from the given roots.
+//
+// Functions that are referenced as values, such as callbacks and handlers,
+// may be called from anywhere, and so are reached by the functions that refer
+// to them. Calls made through an interface reach every method with the same
+// name and signature as the method called.
+func Unreachable(decls map[string]FuncDecl, roots map[string]struct{}) []string {
+	var (
+		reached  = make(map[string]struct{}, len(roots))
+		frontier []string
+		methods  = make(map[string][]string)
+	)
+
+	for name, decl := range decls {
+		if decl.Method != "" {
+			methods[decl.Method] = append(methods[decl.Method], name)
+		}
+	}
+
+	for name := range roots {
+		reached[name] = struct{}{}
+		frontier = append(frontier, name)
+	}
+
+	for len(frontier) != 0 {
+		current := frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+
+		decl := decls[current]
+
+		names := make([]string, 0, len(decl.Calls)+len(decl.References))
+		for _, call := range decl.Calls {
+			names = append(names, call.Name)
+			if call.Method != "" {
+				names = append(names, methods[call.Method]...)
+			}
+		}
+		names = append(names, decl.References...)
+
+		for _, name := range names {
+			if _, found := reached[name]; !found {
+				reached[name] = struct{}{}
+				frontier = append(frontier, name)
+			}
+		}
+	}
+
+	var names []string
+
+	for name, decl := range decls {
+		if _, found := reached[name]; !found && decl.Initial {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// IsExported reports whether the given fully qualified function or method
+// name is exported.
+func IsExported(name string) bool {
+	first, _ := utf8.DecodeRuneInString(unqualified(name))
+	return unicode.IsUpper(first)
+}
+
+// unqualified returns the given fully qualified name without its package or
+// receiver.
+func unqualified(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// hasTestPrefix reports whether the given unqualified name is that of a test,
+// benchmark, example, or fuzz function.
+func hasTestPrefix(name string) bool {
+	if name == "TestMain" {
+		return true
+	}
+
+	for _, prefix := range testPrefixes {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		// As with go test, the prefix must not be followed by a lower case
+		// letter, so that "Testify" is not a test.
+		next, _ := utf8.DecodeRuneInString(name[len(prefix):])
+		if !unicode.IsLower(next) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,255 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package graph
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnreachable(t *testing.T) {
+	cg := map[string]FuncDecl{
+		"cmd.main": {
+			Name:    "cmd.main",
+			Package: "cmd",
+			Initial: true,
+			Calls: []FuncCall{
+				{Name: "cmd.run"},
+				{Name: "cmd.serve"},
+			},
+		},
+		"cmd.run": {
+			Name:    "cmd.run",
+			Package: "cmd",
+			Initial: true,
+			Calls: []FuncCall{
+				{Name: "lib.Parse"},
+			},
+		},
+		"cmd.serve": {
+			Name:       "cmd.serve",
+			Package:    "cmd",
+			Initial:    true,
+			References: []string{"cmd.handle"},
+		},
+		"cmd.handle": {
+			Name:    "cmd.handle",
+			Package: "cmd",
+			Initial: true,
+		},
+		"cmd.Unused": {
+			Name:    "cmd.Unused",
+			Package: "cmd",
+			Initial: true,
+		},
+		"lib.Parse": {
+			Name:    "lib.Parse",
+			Package: "lib",
+			Initial: true,
+			Calls: []FuncCall{
+				{Name: "lib.parse"},
+			},
+		},
+		"lib.parse": {
+			Name:    "lib.parse",
+			Package: "lib",
+			Initial: true,
+		},
+		"lib.init": {
+			Name:    "lib.init",
+			Package: "lib",
+			Initial: true,
+			Calls: []FuncCall{
+				{Name: "lib.register"},
+			},
+		},
+		"lib.register": {
+			Name:    "lib.register",
+			Package: "lib",
+			Initial: true,
+		},
+		"lib.legacy": {
+			Name:    "lib.legacy",
+			Package: "lib",
+			Initial: true,
+		},
+		"(*lib.T).Close": {
+			Name:    "(*lib.T).Close",
+			Package: "lib",
+			Initial: true,
+			Calls: []FuncCall{
+				{Name: "(*lib.T).flush"},
+			},
+		},
+		"(*lib.T).flush": {
+			Name:    "(*lib.T).flush",
+			Package: "lib",
+			Initial: true,
+		},
+		"lib.TestParse": {
+			Name:    "lib.TestParse",
+			Package: "lib",
+			Initial: true,
+			Test:    true,
+			Calls: []FuncCall{
+				{Name: "lib.helper"},
+			},
+		},
+		"lib.helper": {
+			Name:    "lib.helper",
+			Package: "lib",
+			Initial: true,
+			Test:    true,
+		},
+		"cmd.Testify": {
+			Name:    "cmd.Testify",
+			Package: "cmd",
+			Initial: true,
+			Test:    true,
+		},
+		"dep.unused": {
+			Name:    "dep.unused",
+			Package: "dep",
+		},
+	}
+
+	entrypoints := Entrypoints(cg)
+
+	assert.Equal(t, map[string]struct{}{
+		"cmd.main":       {},
+		"lib.Parse":      {},
+		"lib.init":       {},
+		"(*lib.T).Close": {},
+		"lib.TestParse":  {},
+	}, entrypoints)
+
+	assert.Equal(t, []string{"cmd.Testify", "cmd.Unused", "lib.legacy"}, Unreachable(cg, entrypoints))
+}
+
+const referencesSource = `package p
+
+import "sort"
+
+type T struct{}
+
+func (T) Close() {}
+
+func apply(fn func()) { fn() }
+func handle()         {}
+
+func run(t T, values []int) {
+	apply(handle)
+	apply(t.Close)
+	apply(handle)
+	sort.Slice(values, func(i, j int) bool {
+		return less(values[i], values[j])
+	})
+}
+
+func less(a, b int) bool { return a < b }
+func unused()            {}
+`
+
+func TestReferences(t *testing.T) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "p.go", referencesSource, 0)
+	require.NoError(t, err)
+
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+
+	config := types.Config{Importer: importer.Default()}
+	_, err = config.Check("p", fset, []*ast.File{file}, info)
+	require.NoError(t, err)
+
+	decls := Package(fset, info, []*ast.File{file})
+
+	assert.Equal(t, []string{"p.handle", "(p.T).Close"}, decls["p.run"].References)
+	assert.Empty(t, decls["p.apply"].References)
+
+	for name := range decls {
+		decl := decls[name]
+		decl.Initial = true
+		decls[name] = decl
+	}
+
+	assert.Equal(t, []string{"p.unused"}, Unreachable(decls, map[string]struct{}{"p.run": {}}))
+}
+
+const interfacesSource = `package main
+
+import "fmt"
+
+type shape interface {
+	area() float64
+}
+
+type square struct{ side float64 }
+
+func (sq square) area() (a float64) { return sq.side * sq.side }
+
+type circle struct{ radius float64 }
+
+func (c *circle) area() float64 { return 3 * c.radius * c.radius }
+
+type label struct{ text string }
+
+func (l label) area() int { return len(l.text) }
+
+func (l label) String() string { return l.text }
+
+func total(shapes []shape) float64 {
+	var sum float64
+	for _, s := range shapes {
+		sum += s.area()
+	}
+	return sum
+}
+
+func main() {
+	fmt.Println(total([]shape{square{1}, &circle{1}}), label{"a"})
+}
+`
+
+func TestUnreachableInterfaces(t *testing.T) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "main.go", interfacesSource, 0)
+	require.NoError(t, err)
+
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+
+	config := types.Config{Importer: importer.Default()}
+	_, err = config.Check("main", fset, []*ast.File{file}, info)
+	require.NoError(t, err)
+
+	decls := Package(fset, info, []*ast.File{file})
+
+	assert.Equal(t, "area func() float64", decls["(main.square).area"].Method)
+	assert.Equal(t, "area func() float64", decls["main.total"].Calls[0].Method)
+
+	for name := range decls {
+		decl := decls[name]
+		decl.Initial = true
+		decls[name] = decl
+	}
+
+	// The area method of label has a different signature, and String is
+	// only called from within fmt, which is not part of the call graph.
+	assert.Equal(t, []string{"(main.label).String", "(main.label).area"}, Unreachable(decls, Entrypoints(decls)))
+}
@@ -7,35 +7,96 @@ package graph
 import (
 	"go/ast"
 	"go/token"
-
-	"golang.org/x/tools/go/loader"
+	"go/types"
 )
 
 type funcDeclVisitor struct {
-	pkg     *loader.PackageInfo
+	info    *types.Info
 	fset    *token.FileSet
 	current string
 	decls   map[string]FuncDecl
+
+	// blocks maps every call to the control flow block it is made in.
+	blocks map[*ast.CallExpr]int
+
+	// deferred maps every call that is deferred to the call of the defer
+	// statement that defers it.
+	deferred map[*ast.CallExpr]*ast.CallExpr
+
+	// called records the identifiers that name the target of a call.
+	called map[*ast.Ident]struct{}
+
+	// literal is true while visiting the body of a function literal.
+	literal bool
 }
 
 // Visit is intended to traverses the contents of an ast.FuncDecl, and will
 // record the existence of all function calls located within the function body.
 func (v *funcDeclVisitor) Visit(node ast.Node) ast.Visitor {
 
-	// The visitor is only concerned with function calls. If the current node
-	// is not a CallExpr, then no additional processing is done.
+	// Functions that are named other than as the target of a call are used
+	// as values, and so may be called from anywhere.
+	if ident, ok := node.(*ast.Ident); ok {
+		if _, called := v.called[ident]; !called {
+			v.reference(ident)
+		}
+		return v
+	}
+
+	// Calls made from within a function literal are still recorded against
+	// the enclosing function, but are marked as such.
+	if _, ok := node.(*ast.FuncLit); ok {
+		inner := *v
+		inner.literal = true
+		return &inner
+	}
+
+	// The visitor is otherwise only concerned with function calls. If the
+	// current node is not a CallExpr, then no additional processing is done.
 	stmt, ok := node.(*ast.CallExpr)
 	if !ok {
 		return v
 	}
 
+	var ident *ast.Ident
+
+	switch fun := stmt.Fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	}
+
+	if ident != nil {
+		v.called[ident] = struct{}{}
+	}
+
 	// Attempt to fully qualify the function call name and package.
-	if pkgName, funcName, ok := Qualify(v.pkg, stmt); ok {
+	if pkgName, funcName, ok := qualify(v.info, stmt); ok {
 
 		call := FuncCall{
 			Name:     funcName,
 			Package:  pkgName,
 			Position: v.fset.Position(stmt.Pos()).String(),
+			Block:    -1,
+			Literal:  v.literal,
+		}
+
+		if ident != nil {
+			if key, iface := method(v.info, ident); iface {
+				call.Method = key
+			}
+		}
+
+		if block, found := v.blocks[stmt]; found {
+			call.Block = block
+		}
+
+		if outer, found := v.deferred[stmt]; found {
+			call.Deferred = true
+			if block, found := v.blocks[outer]; found {
+				call.Block = block
+			}
 		}
 
 		// Record that this function call exists inside the parent function
@@ -46,6 +107,23 @@ func (v *funcDeclVisitor) Visit(node ast.Node) ast.Visitor {
 	return v
 }
 
+func (v *funcDeclVisitor) reference(ident *ast.Ident) {
+	fn, ok := v.info.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return
+	}
+
+	curr := v.decls[v.current]
+	for _, name := range curr.References {
+		if name == fn.FullName() {
+			return
+		}
+	}
+
+	curr.References = append(curr.References, fn.FullName())
+	v.decls[v.current] = curr
+}
+
 func (v *funcDeclVisitor) add(call FuncCall) {
 	curr := v.decls[v.current]
 	curr.Calls = append(curr.Calls, call)
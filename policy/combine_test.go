@@ -128,7 +128,7 @@ func TestCombine(t *testing.T) {
 		name := fmt.Sprintf("#%d - %s", index, test.title)
 
 		t.Run(name, func(t *testing.T) {
-			actual, err := combineDecls(test.first, test.second, test.match, test.split)
+			actual, err := combineDecls(test.first, test.second, test.match, test.split, sourceOrder)
 
 			if test.err != "" {
 				require.EqualError(t, err, test.err)
@@ -0,0 +1,119 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"sort"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+// deprecated returns a violation for every call to a deprecated function made
+// by a function that may act as a root. If the policy is transitive, roots
+// that only reach a deprecated function through other roots, which are also
+// selected by its intermediary patterns, are given a single violation,
+// following the shortest such chain of calls.
+func deprecated(decls map[string]graph.FuncDecl, policy Policy) []Decl {
+	var (
+		names   = make([]string, 0, len(decls))
+		results []Decl
+	)
+
+	for name := range decls {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	// Calls are only followed through functions that may themselves act as
+	// a root, so that deprecated calls made within dependencies, or within
+	// deprecated functions, are never reported.
+	eligible := func(decl graph.FuncDecl) bool {
+		return decl.Initial && decl.Deprecated == "" && policy.covers(decl)
+	}
+
+	passes := func(decl graph.FuncDecl) bool {
+		return eligible(decl) && policy.Scope.passes(decl) && policy.Intermediaries.selects(decl)
+	}
+
+	for _, name := range names {
+		decl := decls[name]
+		if !eligible(decl) || !policy.Scope.allows(decl) {
+			continue
+		}
+
+		var direct bool
+
+		for index, call := range decl.Calls {
+			if decls[call.Name].Deprecated != "" {
+				results = append(results, deprecation(decls, []step{{name, index}}))
+				direct = true
+			}
+		}
+
+		if direct || !policy.Transitive {
+			continue
+		}
+
+		if steps := nearestDeprecated(decls, name, passes); steps != nil {
+			results = append(results, deprecation(decls, steps))
+		}
+	}
+
+	return results
+}
+
+// nearestDeprecated returns the shortest chain of calls from root to a call to
+// a deprecated function, that only passes through eligible functions, or nil
+// if there is none.
+func nearestDeprecated(decls map[string]graph.FuncDecl, root string, eligible func(graph.FuncDecl) bool) []step {
+	var (
+		previous = map[string]step{root: {}}
+		queue    = []string{root}
+	)
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for index, call := range decls[current].Calls {
+			if decls[call.Name].Deprecated != "" {
+				steps := []step{{current, index}}
+				for steps[0].caller != root {
+					steps = append([]step{previous[steps[0].caller]}, steps...)
+				}
+				return steps
+			}
+
+			callee, found := decls[call.Name]
+			if !found || !eligible(callee) {
+				continue
+			}
+
+			if _, found := previous[call.Name]; found {
+				continue
+			}
+
+			previous[call.Name] = step{current, index}
+			queue = append(queue, call.Name)
+		}
+	}
+
+	return nil
+}
+
+// deprecation returns the chain of calls given by steps, with the deprecation
+// notice of the function that it ends in.
+func deprecation(decls map[string]graph.FuncDecl, steps []step) Decl {
+	decl := chain(decls, steps)
+
+	leaf := &decl
+	for len(leaf.Calls) != 0 {
+		leaf = &leaf.Calls[0].Decl
+	}
+	leaf.Deprecated = decls[leaf.Name].Deprecated
+
+	return decl
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+func TestDeprecated(t *testing.T) {
+	cg := map[string]graph.FuncDecl{
+		"a.main": {
+			Name:    "a.main",
+			Package: "a",
+			Initial: true,
+			Calls: []graph.FuncCall{
+				{Name: "a.load"},
+			},
+		},
+		"a.load": {
+			Name:    "a.load",
+			Package: "a",
+			Initial: true,
+			Calls: []graph.FuncCall{
+				{Name: "a.read"},
+			},
+		},
+		"a.read": {
+			Name:    "a.read",
+			Package: "a",
+			Initial: true,
+			Calls: []graph.FuncCall{
+				{Name: "io.ReadAll"},
+				{Name: "ioutil.ReadAll"},
+			},
+		},
+		"ioutil.ReadAll": {
+			Name:       "ioutil.ReadAll",
+			Package:    "io/ioutil",
+			Deprecated: "Use io.ReadAll instead.",
+		},
+		"io.ReadAll": {
+			Name:    "io.ReadAll",
+			Package: "io",
+		},
+	}
+
+	direct := []Decl{
+		{
+			Name: "a.read",
+			Calls: []Call{{
+				Name:  "ioutil.ReadAll",
+				Index: 1,
+				Decl:  Decl{Name: "ioutil.ReadAll", Deprecated: "Use io.ReadAll instead."},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		transitive     bool
+		intermediaries Patterns
+		expected       []Decl
+	}{
+		{
+			name:     "direct",
+			expected: direct,
+		},
+		{
+			name:           "intermediaries",
+			intermediaries: Patterns{Include: []string{"a"}},
+			expected:       direct,
+		},
+		{
+			name:       "transitive",
+			transitive: true,
+			expected: []Decl{
+				{
+					Name: "a.load",
+					Calls: []Call{{
+						Name: "a.read",
+						Decl: Decl{
+							Name: "a.read",
+							Calls: []Call{{
+								Name:  "ioutil.ReadAll",
+								Index: 1,
+								Decl:  Decl{Name: "ioutil.ReadAll", Deprecated: "Use io.ReadAll instead."},
+							}},
+						},
+					}},
+				},
+				{
+					Name: "a.main",
+					Calls: []Call{{
+						Name: "a.load",
+						Decl: Decl{
+							Name: "a.load",
+							Calls: []Call{{
+								Name: "a.read",
+								Decl: Decl{
+									Name: "a.read",
+									Calls: []Call{{
+										Name:  "ioutil.ReadAll",
+										Index: 1,
+										Decl:  Decl{Name: "ioutil.ReadAll", Deprecated: "Use io.ReadAll instead."},
+									}},
+								},
+							}},
+						},
+					}},
+				},
+				{
+					Name: "a.read",
+					Calls: []Call{{
+						Name:  "ioutil.ReadAll",
+						Index: 1,
+						Decl:  Decl{Name: "ioutil.ReadAll", Deprecated: "Use io.ReadAll instead."},
+					}},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy := Policy{Name: "no-deprecated", ForbidDeprecated: true, Transitive: test.transitive, Intermediaries: test.intermediaries}
+
+			violations := MatchingPaths(cg, policy)
+			assert.Equal(t, test.expected, violations)
+
+			message := Violation{Policy: policy, Decl: violations[0]}.Message()
+			assert.Equal(t, "ioutil.ReadAll is deprecated: Use io.ReadAll instead.", message)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"fmt"
+
+	"github.com/joshdk/callcheck/gitdiff"
+	"github.com/joshdk/callcheck/graph"
+)
+
+// FilterChanged returns only the violations that include a function or call
+// site modified in the given changes, along with a description of what was
+// modified for each returned violation.
+func FilterChanged(violations []Decl, callGraph map[string]graph.FuncDecl, changes gitdiff.Changes) ([]Decl, [][]string) {
+	var (
+		filtered []Decl
+		touched  [][]string
+	)
+
+	for _, violation := range violations {
+		seen := make(map[string]struct{})
+		if modified := changed(violation, callGraph, changes, seen); len(modified) != 0 {
+			filtered = append(filtered, violation)
+			touched = append(touched, modified)
+		}
+	}
+
+	return filtered, touched
+}
+
+// changed describes every function and call site in the given violation that
+// has been modified.
+func changed(decl Decl, callGraph map[string]graph.FuncDecl, changes gitdiff.Changes, seen map[string]struct{}) []string {
+	var modified []string
+
+	if _, found := seen[decl.Name]; !found {
+		seen[decl.Name] = struct{}{}
+
+		fn := callGraph[decl.Name]
+
+		// The function may be declared in a different file in each build
+		// configuration.
+		declarations := append([]graph.Declaration{{Position: fn.Position, End: fn.End}}, fn.Alternates...)
+
+		for _, declaration := range declarations {
+			filename, start, _, ok := graph.ParsePosition(declaration.Position)
+			_, end, _, endOk := graph.ParsePosition(declaration.End)
+
+			if ok && endOk && changes.Overlaps(filename, start, end) {
+				modified = append(modified, fmt.Sprintf("function %s", decl.Name))
+				break
+			}
+		}
+	}
+
+	for _, call := range decl.Calls {
+		if filename, line, _, ok := graph.ParsePosition(call.Position); ok && changes.Contains(filename, line) {
+			modified = append(modified, fmt.Sprintf("call to %s at %s", call.Name, call.Position))
+		}
+
+		modified = append(modified, changed(call.Decl, callGraph, changes, seen)...)
+	}
+
+	return modified
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joshdk/callcheck/gitdiff"
+	"github.com/joshdk/callcheck/graph"
+)
+
+func TestFilterChanged(t *testing.T) {
+
+	decls := map[string]graph.FuncDecl{
+		"main.main": {
+			Name:     "main.main",
+			Position: "/src/main.go:3:1",
+			End:      "/src/main.go:7:1",
+			Calls: []graph.FuncCall{
+				{Name: "main.run", Position: "/src/main.go:5:5"},
+			},
+		},
+		"main.run": {
+			Name:     "main.run",
+			Position: "/src/run.go:3:1",
+			End:      "/src/run.go:6:1",
+			Calls: []graph.FuncCall{
+				{Name: "os.Exit", Position: "/src/run.go:4:9"},
+			},
+			Alternates: []graph.Declaration{
+				{Position: "/src/run_windows.go:3:1", End: "/src/run_windows.go:6:1", Configs: []string{"windows"}},
+			},
+		},
+	}
+
+	// main.main → main.run → os.Exit
+	violation := Decl{
+		Name:     "main.main",
+		Position: "/src/main.go:3:1",
+		Calls: []Call{{
+			Name:     "main.run",
+			Position: "/src/main.go:5:5",
+			Decl: Decl{
+				Name:     "main.run",
+				Position: "/src/run.go:3:1",
+				Calls: []Call{{
+					Name:     "os.Exit",
+					Position: "/src/run.go:4:9",
+					Decl:     Decl{Name: "os.Exit"},
+				}},
+			},
+		}},
+	}
+
+	tests := []struct {
+		title    string
+		changes  gitdiff.Changes
+		modified []string
+	}{
+		{
+			title: "no changes",
+		},
+		{
+			title:   "unrelated file",
+			changes: gitdiff.Changes{"/src/other.go": {{Start: 1, End: 100}}},
+		},
+		{
+			title:   "outside of any function",
+			changes: gitdiff.Changes{"/src/main.go": {{Start: 1, End: 2}, {Start: 8, End: 9}}},
+		},
+		{
+			title:    "root function body",
+			changes:  gitdiff.Changes{"/src/main.go": {{Start: 4, End: 4}}},
+			modified: []string{"function main.main"},
+		},
+		{
+			title:   "call site in root function",
+			changes: gitdiff.Changes{"/src/main.go": {{Start: 5, End: 5}}},
+			modified: []string{
+				"function main.main",
+				"call to main.run at /src/main.go:5:5",
+			},
+		},
+		{
+			title:   "call site in intermediate function",
+			changes: gitdiff.Changes{"/src/run.go": {{Start: 4, End: 4}}},
+			modified: []string{
+				"function main.run",
+				"call to os.Exit at /src/run.go:4:9",
+			},
+		},
+		{
+			title:    "declaration in another build configuration",
+			changes:  gitdiff.Changes{"/src/run_windows.go": {{Start: 5, End: 5}}},
+			modified: []string{"function main.run"},
+		},
+		{
+			title:   "function signature",
+			changes: gitdiff.Changes{"/src/run.go": {{Start: 1, End: 3}}},
+			modified: []string{
+				"function main.run",
+			},
+		},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.title)
+
+		t.Run(name, func(t *testing.T) {
+			filtered, touched := FilterChanged([]Decl{violation}, decls, test.changes)
+
+			if len(test.modified) == 0 {
+				assert.Empty(t, filtered)
+				assert.Empty(t, touched)
+				return
+			}
+
+			assert.Equal(t, []Decl{violation}, filtered)
+			assert.Equal(t, [][]string{test.modified}, touched)
+		})
+	}
+}
+
+func TestFilterChangedRepeatedFunction(t *testing.T) {
+	decls := map[string]graph.FuncDecl{
+		"main.main": {
+			Name:     "main.main",
+			Position: "/src/main.go:3:1",
+			End:      "/src/main.go:7:1",
+		},
+	}
+
+	// Both branches of the violation pass through main.main, which should
+	// only be described once.
+	violation := Decl{
+		Name: "main.main",
+		Calls: []Call{
+			{Name: "main.main", Position: "/src/main.go:4:2", Decl: Decl{Name: "main.main"}},
+			{Name: "os.Exit", Position: "/src/main.go:6:2", Decl: Decl{Name: "os.Exit"}},
+		},
+	}
+
+	changes := gitdiff.Changes{"/src/main.go": {{Start: 6, End: 6}}}
+
+	_, touched := FilterChanged([]Decl{violation}, decls, changes)
+	assert.Equal(t, [][]string{{"function main.main", "call to os.Exit at /src/main.go:6:2"}}, touched)
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Violation is a single decl tree that matched the rule of a policy.
+type Violation struct {
+	Policy Policy
+	Decl   Decl
+}
+
+// WriteDOT writes the given violations in the Graphviz DOT language. Each
+// violation is drawn as its own cluster subgraph. Functions that matched a
+// node of the policy rule are highlighted and labelled with that rule node,
+// and each call is labelled with its position. The message of the violated
+// policy is added to the cluster label, its remediation is used as a tooltip,
+// and its url as a link.
+func WriteDOT(w io.Writer, violations []Violation) error {
+	if _, err := fmt.Fprintln(w, "digraph violations {"); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\tnode [shape=box];")
+
+	for index, violation := range violations {
+		d := dotWriter{
+			w:       w,
+			prefix:  fmt.Sprintf("v%d_", index),
+			indices: ruleIndices(violation.Policy.Rule),
+		}
+
+		fmt.Fprintf(w, "\tsubgraph cluster_%d {\n", index)
+		label := fmt.Sprintf("%s (%d/%d)", violation.Policy.Name, index+1, len(violations))
+		if message := violation.Message(); message != "" {
+			label += "\n" + message
+		}
+
+		fmt.Fprintf(w, "\t\tlabel=%s;\n", strconv.Quote(label))
+		if remediation := violation.Remediation(); remediation != "" {
+			fmt.Fprintf(w, "\t\ttooltip=%s;\n", strconv.Quote(remediation))
+		}
+		if url := violation.URL(); url != "" {
+			fmt.Fprintf(w, "\t\tURL=%s;\n", strconv.Quote(url))
+		}
+
+		d.decl(violation.Decl, []*Node{violation.Policy.Rule})
+
+		fmt.Fprintln(w, "\t}")
+	}
+
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// ruleIndices numbers every node of the given rule in depth-first order, so
+// that matched functions can be labelled with the rule node they matched.
+func ruleIndices(rule *Node) map[*Node]int {
+	indices := make(map[*Node]int)
+	rule.Walk(func(node *Node) {
+		if !node.Combinator() {
+			indices[node] = len(indices) + 1
+		}
+	})
+	return indices
+}
+
+type dotWriter struct {
+	w       io.Writer
+	prefix  string
+	count   int
+	indices map[*Node]int
+}
+
+// decl writes the given decl, and every call beneath it, returning the id of
+// the written node. Candidates are the rule nodes that the decl may match.
+func (d *dotWriter) decl(decl Decl, candidates []*Node) string {
+	id := strconv.Quote(fmt.Sprintf("%s%d", d.prefix, d.count))
+	d.count++
+
+	label := fmt.Sprintf("%s\n%s", fmtUnknown(decl.Name), fmtUnknown(decl.Position))
+	attrs := ""
+
+	// Once a rule node has been matched, its children become the candidates
+	// for the rest of the chain.
+	for _, candidate := range candidates {
+		if candidate != nil && candidate.Matches(decl.Name) {
+			label += fmt.Sprintf("\nrule node %d/%d", d.indices[candidate], len(d.indices))
+			attrs = ", style=filled, fillcolor=lightcoral"
+			candidates = targets(candidate.Calls)
+			break
+		}
+	}
+
+	fmt.Fprintf(d.w, "\t\t%s [label=%s%s];\n", id, strconv.Quote(label), attrs)
+
+	for _, call := range decl.Calls {
+		child := d.decl(call.Decl, candidates)
+		fmt.Fprintf(d.w, "\t\t%s -> %s [label=%s];\n", id, child, strconv.Quote(fmtUnknown(call.Position)))
+	}
+
+	return id
+}
+
+// targets returns the named nodes that may be matched by the given calls,
+// looking through any combinators. Nodes that must not be called are never
+// matched.
+func targets(calls []*Node) []*Node {
+	var nodes []*Node
+
+	for _, call := range calls {
+		switch {
+		case call.Combinator():
+			nodes = append(nodes, targets(call.AnyOf)...)
+			nodes = append(nodes, targets(call.AllOf)...)
+		default:
+			nodes = append(nodes, call)
+		}
+	}
+
+	return nodes
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDOT(t *testing.T) {
+	pol := Policy{
+		Name: "no-exec",
+		Rule: &Node{
+			Name: "main",
+			Calls: []*Node{
+				{Name: "exec"},
+			},
+		},
+	}
+
+	violation := Decl{
+		Name:     "main",
+		Position: "main.go:0",
+		Calls: []Call{
+			{
+				Name:     "run",
+				Position: "main.go:1",
+				Decl: Decl{
+					Name:     "run",
+					Position: "run.go:0",
+					Calls: []Call{
+						{
+							Name:     "exec",
+							Position: "run.go:1",
+							Decl: Decl{
+								Name:     "exec",
+								Position: "exec.go:0",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := `
+		digraph violations {
+			node [shape=box];
+			subgraph cluster_0 {
+				label="no-exec (1/1)";
+				"v0_0" [label="main\nmain.go:0\nrule node 1/2", style=filled, fillcolor=lightcoral];
+				"v0_1" [label="run\nrun.go:0"];
+				"v0_2" [label="exec\nexec.go:0\nrule node 2/2", style=filled, fillcolor=lightcoral];
+				"v0_1" -> "v0_2" [label="run.go:1"];
+				"v0_0" -> "v0_1" [label="main.go:1"];
+			}
+		}
+	`
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteDOT(&buffer, []Violation{{Policy: pol, Decl: violation}}))
+
+	assert.Equal(t, strings.TrimSpace(unindent(expected)), strings.TrimSpace(unindent(buffer.String())))
+}
@@ -6,57 +6,219 @@ package policy
 
 import (
 	"errors"
+	"sort"
 
 	"github.com/joshdk/callcheck/graph"
 )
 
 type Decl struct {
-	Position string
-	Name     string
-	Calls    []Call
+	Position string `json:"position"`
+	Name     string `json:"name"`
+	Calls    []Call `json:"calls,omitempty"`
+
+	// Deprecated is the deprecation notice of this function. It is only
+	// set on the deprecated function at the end of a violation of a
+	// ForbidDeprecated policy.
+	Deprecated string `json:"deprecated,omitempty"`
 }
 
 type Call struct {
-	Position string
-	Name     string
-	Decl     Decl
-	Index    int
+	Position string `json:"position"`
+	Name     string `json:"name"`
+	Decl     Decl   `json:"decl"`
+	Index    int    `json:"-"`
 }
 
 type Goal struct {
 	startDecl string
 	endCall   string
+
+	// maxDepth is the maximum number of calls that a path from startDecl to
+	// endCall may take, or zero if unbounded.
+	maxDepth int
 }
 
 func MatchingPaths(graph map[string]graph.FuncDecl, policy Policy) []Decl {
-	if policy.Rule == nil {
+	if graph == nil {
 		return nil
 	}
 
-	if graph == nil {
+	if policy.Pair != nil {
+		return unpaired(restrict(graph, policy), policy)
+	}
+
+	if policy.ForbidRecursion {
+		// Cycles may pass through functions outside of the included
+		// packages, so long as one of their functions is included.
+		return recursion(restrict(graph, policy), policy)
+	}
+
+	if policy.ForbidDeprecated {
+		// Deprecated functions are usually declared outside of the
+		// included packages, or the intermediaries, so only the functions
+		// that calls pass through are checked against them.
+		return deprecated(graph, policy)
+	}
+
+	if policy.Rule == nil {
 		return nil
 	}
 
+	graph = restrict(graph, policy)
+
+	var (
+		results []Decl
+		reach   = newReachability(graph)
+	)
+
+	// Match the rule separately for every function that may act as its root.
+	for _, root := range roots(graph, policy) {
+		rule := *policy.Rule
+		rule.Name = root
+
+		results = append(results, matchRule(graph, &rule, reach)...)
+	}
+
+	return results
+}
+
+// roots returns the names of the functions that may act as the root of a
+// violation of the given policy. A rule whose root is a glob only matches
+// functions declared in the packages being checked, rather than in their
+// dependencies.
+func roots(decls map[string]graph.FuncDecl, policy Policy) []string {
+	if !graph.IsGlob(policy.Rule.Name) {
+		if decl, found := decls[policy.Rule.Name]; found && !policy.Scope.allows(decl) {
+			return nil
+		}
+		return []string{policy.Rule.Name}
+	}
+
+	var names []string
+
+	// Functions named by the calls of the rule are kept by restrict, even if
+	// the policy does not cover them, but they may not act as its root.
+	for name, decl := range decls {
+		if decl.Initial && policy.Rule.Matches(name) && policy.Scope.allows(decl) && policy.covers(decl) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// restrict returns the given call graph without any functions that violations
+// of the given policy may not pass through, either because they are test
+// functions and the scope excludes them, or because they are not covered by
+// the include and exclude patterns. Functions named by the rule, other than
+// its root, are always kept.
+func restrict(decls map[string]graph.FuncDecl, policy Policy) map[string]graph.FuncDecl {
+	named := make(map[string]struct{})
+	if policy.Rule != nil {
+		for _, call := range policy.Rule.Calls {
+			call.Walk(func(node *Node) {
+				named[node.Name] = struct{}{}
+			})
+		}
+	}
+
+	var filtered map[string]graph.FuncDecl
+
+	for name, decl := range decls {
+		if _, found := named[name]; found {
+			continue
+		}
+
+		if policy.Scope.passes(decl) && policy.Intermediaries.selects(decl) {
+			continue
+		}
+
+		// Only copy the graph if it actually contains functions to remove.
+		if filtered == nil {
+			filtered = make(map[string]graph.FuncDecl, len(decls))
+			for name, decl := range decls {
+				filtered[name] = decl
+			}
+		}
+
+		delete(filtered, name)
+	}
+
+	if filtered == nil {
+		return decls
+	}
+
+	return filtered
+}
+
+// matchRule returns every violation of the given rule, whose root must name a
+// single function.
+func matchRule(graph map[string]graph.FuncDecl, rule *Node, reach *reachability) []Decl {
 	// Our rule does not have had any calls, and must be a decl all on its own.
-	if len(policy.Rule.Calls) == 0 {
+	if len(rule.Calls) == 0 {
 		// Check that our rule actually exist in the graph.
-		if decl, found := graph[policy.Rule.Name]; found {
+		if decl, found := graph[rule.Name]; found {
 			return []Decl{{Name: decl.Name, Position: decl.Position}}
 		}
 		return nil
 	}
 
-	nodeToGoalMapping := splitRule(policy.Rule)
+	// Check that our rule actually exist in the graph.
+	if _, found := graph[rule.Name]; !found {
+		return nil
+	}
+
+	nodeToGoalMapping := splitRule(rule)
 	goalToDeclsMapping := make(map[*Goal][]Decl)
+	for _, goal := range nodeToGoalMapping {
+		goalToDeclsMapping[goal] = walk(goal.startDecl, goal.endCall, goal.maxDepth, graph, reach.to(goal.endCall))
+	}
+
+	m := matcher{
+		decls: graph,
+		goals: nodeToGoalMapping,
+		paths: goalToDeclsMapping,
+	}
+
+	return m.genMatches(rule)
+}
+
+// Paths returns the paths through the given call graph from the function
+// named start to the function named end. Every returned path is linear.
+func Paths(graph map[string]graph.FuncDecl, start string, end string) []Decl {
+	return walker(start, end, graph)
+}
 
-	for _, goals := range nodeToGoalMapping {
-		for _, goal := range goals {
-			decls := walker(goal.startDecl, goal.endCall, graph)
-			goalToDeclsMapping[goal] = decls
+// ShortestPaths returns only the paths with the fewest calls.
+func ShortestPaths(paths []Decl) []Decl {
+	var (
+		shortest []Decl
+		fewest   = -1
+	)
+
+	for _, path := range paths {
+		switch length := pathLength(path); {
+		case fewest == -1 || length < fewest:
+			fewest = length
+			shortest = []Decl{path}
+		case length == fewest:
+			shortest = append(shortest, path)
 		}
 	}
 
-	return genMatches(policy.Rule, nodeToGoalMapping, goalToDeclsMapping)
+	return shortest
+}
+
+// pathLength returns the number of calls in the given linear path.
+func pathLength(path Decl) int {
+	var length int
+	for len(path.Calls) != 0 {
+		path = path.Calls[0].Decl
+		length++
+	}
+	return length
 }
 
 // walker traverses the given call graph from the function named start and
@@ -64,12 +226,62 @@ func MatchingPaths(graph map[string]graph.FuncDecl, policy Policy) []Decl {
 // returned if no paths are found. All returned paths are guaranteed to be
 // linear (do not branch).
 func walker(start string, end string, graph map[string]graph.FuncDecl) []Decl {
-	visited := make(map[string]struct{})
-	return paths(start, end, visited, graph)
+	return walk(start, end, 0, graph, newReachability(graph).to(end))
+}
+
+// walk is walker, given the functions that can reach end. Only those
+// functions are explored, as no other function can contribute a path. If
+// maxDepth is not zero, only paths of at most that many calls are returned.
+func walk(start string, end string, maxDepth int, decls map[string]graph.FuncDecl, reaching map[string]struct{}) []Decl {
+	visited := make(map[string]int)
+
+	remaining := maxDepth
+	if remaining == 0 {
+		remaining = unbounded
+	}
+
+	return paths(start, end, remaining, visited, reaching, decls)
+}
+
+// unbounded is the remaining depth of paths that have no maximum depth.
+const unbounded = -1
+
+// reverse builds a reverse index of the given call graph.
+func reverse(decls map[string]graph.FuncDecl) map[string][]graph.FuncCall {
+	return graph.Callers(decls)
+}
+
+// reachability finds the functions that can reach a given function. The
+// reverse index of the call graph, and the functions that can reach each end,
+// are only built once, so that they are shared by every root of a rule.
+type reachability struct {
+	callers  map[string][]graph.FuncCall
+	reaching map[string]map[string]struct{}
+}
+
+func newReachability(decls map[string]graph.FuncDecl) *reachability {
+	return &reachability{
+		callers:  reverse(decls),
+		reaching: make(map[string]map[string]struct{}),
+	}
+}
+
+// to returns the names of the functions that can reach the function named
+// end, including end itself.
+func (r *reachability) to(end string) map[string]struct{} {
+	reaching, found := r.reaching[end]
+	if !found {
+		reaching = graph.Reaching(r.callers, end)
+		r.reaching[end] = reaching
+	}
+	return reaching
 }
 
-// paths is an internal function behind walker.
-func paths(current string, end string, visited map[string]struct{}, graph map[string]graph.FuncDecl) []Decl {
+// paths is an internal function behind walker. Remaining is the number of
+// calls that may still be taken, or unbounded. Visited records the most
+// remaining calls that each function has been explored with, so that a
+// function is only explored again if more of the path is left.
+func paths(current string, end string, remaining int, visited map[string]int, reaching map[string]struct{}, graph map[string]graph.FuncDecl) []Decl {
 	if graph == nil {
 		return nil
 	}
@@ -83,18 +295,31 @@ func paths(current string, end string, visited map[string]struct{}, graph map[st
 		return []Decl{me}
 	}
 
-	if _, found := visited[current]; found {
+	if seen, found := visited[current]; found && (remaining == unbounded || seen >= remaining) {
+		return nil
+	}
+
+	if remaining == 0 {
 		return nil
 	}
 
 	startDecl := graph[current]
 
-	visited[current] = struct{}{}
+	visited[current] = remaining
+
+	next := remaining
+	if next != unbounded {
+		next--
+	}
 
 	var results []Decl
 
 	for index, call := range startDecl.Calls {
-		paths := paths(call.Name, end, visited, graph)
+		if _, found := reaching[call.Name]; !found {
+			continue
+		}
+
+		paths := paths(call.Name, end, next, visited, reaching, graph)
 		for _, path := range paths {
 			results = append(results, Decl{
 				Name:     current,
@@ -114,159 +339,316 @@ func paths(current string, end string, visited map[string]struct{}, graph map[st
 	return results
 }
 
-func walkRule(node *Node, goals map[*Node][]*Goal) {
-	if node == nil {
-		return
-	}
-
-	for _, call := range node.Calls {
-		if _, found := goals[node]; !found {
-			goals[node] = []*Goal{}
+// walkRule records a goal for every named node in the given list, looking
+// through any combinators, whose start is the function named parent.
+func walkRule(parent string, nodes []*Node, goals map[*Node]*Goal) {
+	for _, node := range nodes {
+		if node.Combinator() {
+			walkRule(parent, node.AnyOf, goals)
+			walkRule(parent, node.AllOf, goals)
+			walkRule(parent, node.NoneOf, goals)
+			continue
 		}
 
-		goals[node] = append(goals[node], &Goal{node.Name, call.Name})
-		walkRule(call, goals)
+		goals[node] = &Goal{parent, node.Name, node.depth()}
+		walkRule(node.Name, node.Calls, goals)
 	}
 }
 
-// splitRule splits the given node into a list of goals for every sub-node.
-func splitRule(node *Node) map[*Node][]*Goal {
-	goals := make(map[*Node][]*Goal)
-	walkRule(node, goals)
+// splitRule splits the given node into a goal for every named sub-node.
+func splitRule(node *Node) map[*Node]*Goal {
+	goals := make(map[*Node]*Goal)
+	walkRule(node.Name, node.Calls, goals)
 	return goals
 }
 
-func combineDecls(first Decl, second Decl, mustMatch string, mustSplit string) (Decl, error) {
-	// Sanity check declarations.
-	switch {
-	case first.Name == "" || second.Name == "":
-		panic("name is blank")
+// matcher assembles violations of a rule from the paths found for each of
+// its goals.
+type matcher struct {
+	decls map[string]graph.FuncDecl
+	goals map[*Node]*Goal
+	paths map[*Goal][]Decl
+}
 
-		// Nodes are supposed to match, but didn't
-	case first.Name == mustMatch && second.Name != first.Name:
-		return Decl{}, errors.New("declarations required to match but did not")
+// partial is a decl tree that matches some of the calls of a rule node. Split
+// names the function that the last matched call ended at, which later calls
+// may not pass through.
+type partial struct {
+	decl  Decl
+	split string
+}
 
-		// Nodes are not supposed to match, but did
-	case first.Name == mustSplit && second.Name == first.Name:
-		return Decl{}, errors.New("declarations required to not match but did")
+// genMatches returns every decl tree rooted at the function named by current
+// that matches all of its calls.
+func (m matcher) genMatches(current *Node) []Decl {
+	partials := m.group(current.Name, current.Calls, m.order(current))
+	if len(partials) == 0 {
+		return nil
+	}
 
-	case len(second.Calls) > 1:
-		panic("more than 1 call")
+	results := make([]Decl, len(partials))
+	for index, partial := range partials {
+		results[index] = partial.decl
+	}
 
-	case first.Name != second.Name:
-		panic("disjoint declarations")
+	return results
+}
+
+// group returns every decl tree rooted at the function named parent that
+// matches all of the given calls. If follows is not nil, the calls must be
+// made in the given order.
+func (m matcher) group(parent string, calls []*Node, follows follows) []partial {
+	all := []partial{m.empty(parent)}
+
+	for _, call := range calls {
+		matches := m.call(parent, call)
+		if len(matches) == 0 {
+			return nil
+		}
 
+		all = combinePartials(all, matches, parent, follows)
+		if len(all) == 0 {
+			return nil
+		}
 	}
 
-	// Nodes are the same, merge
-	merged, err := combineCalls(first.Calls, second.Calls, mustMatch, mustSplit)
-	if err != nil {
-		return Decl{}, err
+	// Unordered calls that are repeated may match the same calls in more
+	// than one way.
+	if follows == nil {
+		all = uniquePartials(all)
 	}
 
-	return Decl{
-		Name:     first.Name,
-		Position: first.Position,
-		Calls:    merged,
-	}, nil
+	return all
 }
 
-func combineCalls(first []Call, second []Call, mustMatch string, mustSplit string) ([]Call, error) {
-	// Sanity check calls.
-	switch {
-	case len(first) == 0 && len(second) == 0:
-		return nil, nil
+// uniquePartials removes partial matches with identical decl trees.
+func uniquePartials(partials []partial) []partial {
+	var (
+		results []partial
+		seen    = make(map[string]struct{}, len(partials))
+	)
+
+	for _, partial := range partials {
+		key := partial.decl.String()
+		if _, found := seen[key]; found {
+			continue
+		}
 
-	case len(second) >= 2:
-		panic("more than 1 call")
+		seen[key] = struct{}{}
+		results = append(results, partial)
+	}
 
-	case len(first) == 0 && len(second) >= 1:
-		panic("first had no calls but second had calls")
+	return results
+}
 
-	case len(first) >= 1 && len(second) == 0:
-		panic("first had calls but second had no calls")
-	}
+// call returns every decl tree rooted at the function named parent that
+// matches the given call, which may be a combinator.
+func (m matcher) call(parent string, call *Node) []partial {
+	switch {
+	case len(call.AnyOf) != 0:
+		var results []partial
+		for _, alternative := range call.AnyOf {
+			results = append(results, m.call(parent, alternative)...)
+		}
+		return results
 
-	firstCall, secondCall := first[0], second[0]
+	case len(call.AllOf) != 0:
+		return m.group(parent, call.AllOf, nil)
 
-	// These two calls are the same, merge them.
-	if firstCall.Name == secondCall.Name {
-		merged, err := combineDecls(firstCall.Decl, secondCall.Decl, mustMatch, mustSplit)
-		if err != nil {
-			return nil, err
+	case len(call.NoneOf) != 0:
+		for _, alternative := range call.NoneOf {
+			if len(m.call(parent, alternative)) != 0 {
+				return nil
+			}
 		}
 
-		return []Call{{
-			Name:     firstCall.Name,
-			Position: firstCall.Position,
-			Index:    firstCall.Index,
-			Decl:     merged,
-		}}, nil
+		// Absent calls do not contribute to the decl tree.
+		return []partial{m.empty(parent)}
 	}
 
-	// These two calls are not the same, check if they are ordered.
-	if firstCall.Index >= secondCall.Index {
-		return nil, errors.New("calls are not sequential")
+	wrappers := m.paths[m.goals[call]]
+	if len(wrappers) == 0 {
+		return nil
 	}
 
-	// Calls are ordered.
-	return []Call{
-		firstCall,
-		secondCall,
-	}, nil
+	wraps := m.genMatches(call)
+	if len(wraps) == 0 {
+		return nil
+	}
+
+	decls := wrapDeclSets(wrappers, wraps)
+
+	results := make([]partial, len(decls))
+	for index, decl := range decls {
+		results[index] = partial{decl, call.Name}
+	}
+
+	return results
 }
 
-func genMatches(current *Node, nodeToGoalMapping map[*Node][]*Goal, goalToDeclsMapping map[*Goal][]Decl) []Decl {
-	var all []Decl
-	split := ""
+// follows reports whether the call second, made by the function named caller,
+// is made after the call first.
+type follows func(caller string, first Call, second Call) bool
 
-	goals := nodeToGoalMapping[current]
+// sourceOrder orders calls as they appear in the source of their caller.
+func sourceOrder(_ string, first Call, second Call) bool {
+	return first.Index < second.Index
+}
 
-	if len(goals) == 0 {
+// order returns how the calls of the given node must be ordered, or nil if
+// they may be made in any order.
+func (m matcher) order(node *Node) follows {
+	if !node.ordered() {
 		return nil
 	}
 
-	for index, call := range current.Calls {
-		wraps := genMatches(call, nodeToGoalMapping, goalToDeclsMapping)
+	switch node.Order {
+	case OrderReachable:
+		return m.flow(graph.FuncDecl.Reachable)
+	case OrderPostDominates:
+		return m.flow(graph.FuncDecl.PostDominates)
+	default:
+		return sourceOrder
+	}
+}
 
-		wrappers := goalToDeclsMapping[goals[index]]
-		if len(wrappers) == 0 {
-			return nil
+// flow orders calls using the given control flow relation of their caller,
+// falling back to source order if the control flow of a call is unknown.
+func (m matcher) flow(relation func(graph.FuncDecl, int, int) (bool, bool)) follows {
+	return func(caller string, first Call, second Call) bool {
+		if related, known := relation(m.decls[caller], first.Index, second.Index); known {
+			return related
 		}
+		return sourceOrder(caller, first, second)
+	}
+}
+
+// empty returns a decl tree for the function named parent, that has no calls.
+func (m matcher) empty(parent string) partial {
+	return partial{decl: Decl{Name: parent, Position: m.decls[parent].Position}}
+}
 
-		res := wrapDeclSets(wrappers, wraps)
+// combinePartials combines every pair of partial matches from the given sets.
+func combinePartials(firstSet []partial, secondSet []partial, mustMatch string, follows follows) []partial {
+	results := make([]partial, 0, len(firstSet)*len(secondSet))
 
-		all = combineDeclSets(all, res, current.Name, split)
+	for _, first := range firstSet {
+		for _, second := range secondSet {
+			combined, err := combineDecls(first.decl, second.decl, mustMatch, first.split, follows)
+			if err != nil {
+				continue
+			}
 
-		split = call.Name
+			split := second.split
+			if split == "" {
+				split = first.split
+			}
+
+			results = append(results, partial{combined, split})
+		}
 	}
 
-	return all
+	return results
 }
 
-func combineDeclSets(firstSet []Decl, secondSet []Decl, mustMatch string, mustSplit string) []Decl {
-	if len(secondSet) == 0 {
-		return nil
+// combineDecls merges the decl trees first and second, which must both be
+// rooted at the same function. Only the root is required to be shared when
+// it is named mustMatch, and second may not pass through the function named
+// mustSplit. If follows is not nil, the calls of second must follow those of
+// first.
+func combineDecls(first Decl, second Decl, mustMatch string, mustSplit string, follows follows) (Decl, error) {
+	// Sanity check declarations.
+	switch {
+	case first.Name == "" || second.Name == "":
+		panic("name is blank")
+
+		// Nodes are supposed to match, but didn't
+	case first.Name == mustMatch && second.Name != first.Name:
+		return Decl{}, errors.New("declarations required to match but did not")
+
+		// Nodes are not supposed to match, but did
+	case first.Name == mustSplit && second.Name == first.Name:
+		return Decl{}, errors.New("declarations required to not match but did")
+
+	case first.Name != second.Name:
+		panic("disjoint declarations")
+
 	}
 
-	if len(firstSet) == 0 {
-		return secondSet
+	// Nodes are the same, merge
+	merged, err := combineCalls(first.Name, first.Calls, second.Calls, mustMatch, mustSplit, follows)
+	if err != nil {
+		return Decl{}, err
 	}
 
-	results := make([]Decl, 0, len(firstSet)*len(secondSet))
+	return Decl{
+		Name:     first.Name,
+		Position: first.Position,
+		Calls:    merged,
+	}, nil
+}
 
-	for _, first := range firstSet {
-		for _, second := range secondSet {
-			combined, err := combineDecls(first, second, mustMatch, mustSplit)
-			if err != nil {
+// combineCalls merges every call in second into the calls in first, which are
+// all made by the function named caller. A call to the same function as an
+// existing call is merged with it, if possible. If follows is not nil, each
+// call must otherwise follow the last call in first.
+func combineCalls(caller string, first []Call, second []Call, mustMatch string, mustSplit string, follows follows) ([]Call, error) {
+	results := append([]Call{}, first...)
+
+	for _, secondCall := range second {
+		if len(results) == 0 {
+			results = append(results, secondCall)
+			continue
+		}
+
+		// Ordered calls may only be merged with the last call so far, as
+		// merging with an earlier call would go back in time.
+		candidates := results
+		if follows != nil {
+			candidates = results[len(results)-1:]
+		}
+
+		merged := false
+
+		for index, firstCall := range candidates {
+			// These two calls are the same, merge them.
+			if firstCall.Name != secondCall.Name {
 				continue
 			}
 
-			results = append(results, combined)
+			decl, err := combineDecls(firstCall.Decl, secondCall.Decl, mustMatch, mustSplit, follows)
+			if err != nil {
+				// Calls from different call sites may still be kept
+				// separately, such as when a rule repeats a call.
+				if firstCall.Index != secondCall.Index {
+					continue
+				}
+				return nil, err
+			}
+
+			candidates[index].Decl = decl
+			merged = true
+			break
+		}
+
+		if merged {
+			continue
+		}
+
+		// These two calls are not the same, check if they are ordered.
+		if follows != nil && !follows(caller, results[len(results)-1], secondCall) {
+			return nil, errors.New("calls are not sequential")
 		}
+
+		results = append(results, secondCall)
 	}
 
-	return results
+	// Unordered calls are kept in the order that they are made.
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Index < results[j].Index
+	})
+
+	return results, nil
 }
 
 // wrapDecl appends the decl tree second onto the end of the linear decl
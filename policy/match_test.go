@@ -394,3 +394,61 @@ func TestWalk(t *testing.T) {
 func unindent(body string) string {
 	return strings.Replace(body, "\t", "", -1)
 }
+
+func TestShortestPaths(t *testing.T) {
+
+	// path builds a linear path through the given functions.
+	path := func(names ...string) Decl {
+		decl := Decl{Name: names[len(names)-1]}
+		for index := len(names) - 2; index >= 0; index-- {
+			decl = Decl{
+				Name:  names[index],
+				Calls: []Call{{Name: decl.Name, Decl: decl}},
+			}
+		}
+		return decl
+	}
+
+	tests := []struct {
+		title    string
+		paths    []Decl
+		expected []Decl
+	}{
+		{
+			title: "no paths",
+		},
+		{
+			title:    "single path",
+			paths:    []Decl{path("a", "b", "c")},
+			expected: []Decl{path("a", "b", "c")},
+		},
+		{
+			title:    "shorter path first",
+			paths:    []Decl{path("a", "c"), path("a", "b", "c")},
+			expected: []Decl{path("a", "c")},
+		},
+		{
+			title:    "shorter path last",
+			paths:    []Decl{path("a", "b", "c"), path("a", "d", "e", "c"), path("a", "c")},
+			expected: []Decl{path("a", "c")},
+		},
+		{
+			title:    "several shortest paths",
+			paths:    []Decl{path("a", "b", "c"), path("a", "d", "e", "c"), path("a", "f", "c")},
+			expected: []Decl{path("a", "b", "c"), path("a", "f", "c")},
+		},
+		{
+			title:    "path to same function",
+			paths:    []Decl{path("a", "b", "a"), path("a")},
+			expected: []Decl{path("a")},
+		},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.title)
+
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ShortestPaths(test.paths))
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// Fields holds the values available to the message, remediation, and url
+// templates of a policy.
+type Fields struct {
+	// Policy is the name of the violated policy.
+	Policy string
+
+	// Root is the name of the function at the root of the violation.
+	Root string
+
+	// Leaf is the name of the last function called by the violation.
+	Leaf string
+
+	// Leaves lists the name of every function at the end of a call chain in
+	// the violation, in order.
+	Leaves []string
+
+	// Deprecated is the deprecation notice of the deprecated function called
+	// by the violation, if any.
+	Deprecated string
+}
+
+// deprecatedMessage is the message of ForbidDeprecated policies that do not
+// have their own.
+const deprecatedMessage = "{{.Leaf}} is deprecated: {{.Deprecated}}"
+
+// Fields returns the template values for this violation.
+func (violation Violation) Fields() Fields {
+	fields := Fields{
+		Policy: violation.Policy.Name,
+		Root:   violation.Decl.Name,
+		Leaves: leaves(violation.Decl),
+	}
+
+	if len(fields.Leaves) != 0 {
+		fields.Leaf = fields.Leaves[len(fields.Leaves)-1]
+	}
+
+	leaf := violation.Decl
+	for len(leaf.Calls) != 0 {
+		leaf = leaf.Calls[len(leaf.Calls)-1].Decl
+	}
+	fields.Deprecated = leaf.Deprecated
+
+	return fields
+}
+
+// Message returns the rendered message of the violated policy.
+func (violation Violation) Message() string {
+	if violation.Policy.Message == "" && violation.Policy.ForbidDeprecated {
+		return violation.render(deprecatedMessage)
+	}
+	return violation.render(violation.Policy.Message)
+}
+
+// Remediation returns the rendered remediation of the violated policy.
+func (violation Violation) Remediation() string {
+	return violation.render(violation.Policy.Remediation)
+}
+
+// URL returns the rendered documentation url of the violated policy.
+func (violation Violation) URL() string {
+	return violation.render(violation.Policy.URL)
+}
+
+// render executes the given template with the fields of this violation. If
+// the template is invalid, it is returned unrendered.
+func (violation Violation) render(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	rendered, err := execute(text, violation.Fields())
+	if err != nil {
+		return text
+	}
+
+	return rendered
+}
+
+// CheckTemplate reports whether the given text is a valid template, which only
+// refers to the available Fields.
+func CheckTemplate(text string) error {
+	_, err := execute(text, Fields{})
+	return err
+}
+
+func execute(text string, fields Fields) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, fields); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// leaves returns the name of every function at the end of a call chain in the
+// given decl tree, in depth-first order.
+func leaves(decl Decl) []string {
+	if len(decl.Calls) == 0 {
+		return []string{decl.Name}
+	}
+
+	var names []string
+	for _, call := range decl.Calls {
+		names = append(names, leaves(call.Decl)...)
+	}
+
+	return names
+}
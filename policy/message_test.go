@@ -0,0 +1,46 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViolationMessage(t *testing.T) {
+	violation := Violation{
+		Policy: Policy{
+			Name:        "no-sleep",
+			Message:     "{{.Root}} calls {{.Leaf}}",
+			Remediation: "use {{.Root}}'s injected clock instead of {{.Leaf}}",
+			URL:         "https://example.com/policies/{{.Policy}}",
+		},
+		Decl: Decl{
+			Name: "main.run",
+			Calls: []Call{
+				{
+					Name: "main.wait",
+					Decl: Decl{
+						Name: "main.wait",
+						Calls: []Call{
+							{Name: "time.Sleep", Decl: Decl{Name: "time.Sleep"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, Fields{Policy: "no-sleep", Root: "main.run", Leaf: "time.Sleep", Leaves: []string{"time.Sleep"}}, violation.Fields())
+	assert.Equal(t, "main.run calls time.Sleep", violation.Message())
+	assert.Equal(t, "use main.run's injected clock instead of time.Sleep", violation.Remediation())
+	assert.Equal(t, "https://example.com/policies/no-sleep", violation.URL())
+
+	violation.Policy.Message = "{{.Missing}}"
+	assert.Equal(t, "{{.Missing}}", violation.Message())
+	assert.Error(t, CheckTemplate("{{.Missing}}"))
+	assert.NoError(t, CheckTemplate("{{.Root}} {{range .Leaves}}{{.}}{{end}}"))
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"github.com/joshdk/callcheck/graph"
+)
+
+// Neighbours builds a tree of the functions adjacent to root in the given
+// index, such as one returned by graph.Callers or graph.Callees, up to depth
+// edges away. A negative depth follows edges without limit.
+func Neighbours(root string, decls map[string]graph.FuncDecl, edges map[string][]graph.FuncCall, depth int) Decl {
+	return neighbours(root, decls, edges, make(map[string]struct{}), depth)
+}
+
+// neighbours builds a tree of the functions adjacent to current in the given
+// index, up to depth edges away. Functions are only expanded the first time
+// they are encountered.
+func neighbours(current string, decls map[string]graph.FuncDecl, edges map[string][]graph.FuncCall, visited map[string]struct{}, depth int) Decl {
+	decl := Decl{
+		Name:     current,
+		Position: decls[current].Position,
+	}
+
+	if _, found := visited[current]; found || depth == 0 {
+		return decl
+	}
+
+	visited[current] = struct{}{}
+
+	for index, edge := range edges[current] {
+		decl.Calls = append(decl.Calls, Call{
+			Name:     edge.Name,
+			Position: edge.Position,
+			Index:    index,
+			Decl:     neighbours(edge.Name, decls, edges, visited, depth-1),
+		})
+	}
+
+	return decl
+}
@@ -0,0 +1,115 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+func TestNeighbours(t *testing.T) {
+
+	decls := map[string]graph.FuncDecl{
+		"main": {
+			Name:     "main",
+			Position: "main.go:1:1",
+			Calls: []graph.FuncCall{
+				{Name: "run", Position: "main.go:2:2"},
+				{Name: "exit", Position: "main.go:3:2"},
+			},
+		},
+		"run": {
+			Name:     "run",
+			Position: "run.go:1:1",
+			Calls: []graph.FuncCall{
+				{Name: "run", Position: "run.go:2:2"},
+				{Name: "exit", Position: "run.go:3:2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		title    string
+		root     string
+		edges    map[string][]graph.FuncCall
+		depth    int
+		expected Decl
+	}{
+		{
+			title:    "no depth",
+			root:     "main",
+			edges:    graph.Callees(decls),
+			depth:    0,
+			expected: Decl{Name: "main", Position: "main.go:1:1"},
+		},
+		{
+			title: "callees of main",
+			root:  "main",
+			edges: graph.Callees(decls),
+			depth: 1,
+			expected: Decl{
+				Name:     "main",
+				Position: "main.go:1:1",
+				Calls: []Call{
+					{Name: "run", Position: "main.go:2:2", Index: 0, Decl: Decl{Name: "run", Position: "run.go:1:1"}},
+					{Name: "exit", Position: "main.go:3:2", Index: 1, Decl: Decl{Name: "exit"}},
+				},
+			},
+		},
+		{
+			title: "recursive callees are expanded once",
+			root:  "main",
+			edges: graph.Callees(decls),
+			depth: -1,
+			expected: Decl{
+				Name:     "main",
+				Position: "main.go:1:1",
+				Calls: []Call{
+					{Name: "run", Position: "main.go:2:2", Index: 0, Decl: Decl{
+						Name:     "run",
+						Position: "run.go:1:1",
+						Calls: []Call{
+							{Name: "run", Position: "run.go:2:2", Index: 0, Decl: Decl{Name: "run", Position: "run.go:1:1"}},
+							{Name: "exit", Position: "run.go:3:2", Index: 1, Decl: Decl{Name: "exit"}},
+						},
+					}},
+					{Name: "exit", Position: "main.go:3:2", Index: 1, Decl: Decl{Name: "exit"}},
+				},
+			},
+		},
+		{
+			title: "callers of exit",
+			root:  "exit",
+			edges: graph.Callers(decls),
+			depth: 2,
+			expected: Decl{
+				Name: "exit",
+				Calls: []Call{
+					{Name: "main", Position: "main.go:3:2", Index: 0, Decl: Decl{Name: "main", Position: "main.go:1:1"}},
+					{Name: "run", Position: "run.go:3:2", Index: 1, Decl: Decl{
+						Name:     "run",
+						Position: "run.go:1:1",
+						Calls: []Call{
+							{Name: "main", Position: "main.go:2:2", Index: 0, Decl: Decl{Name: "main", Position: "main.go:1:1"}},
+							{Name: "run", Position: "run.go:2:2", Index: 1, Decl: Decl{Name: "run", Position: "run.go:1:1"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.title)
+
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Neighbours(test.root, decls, test.edges, test.depth))
+		})
+	}
+}
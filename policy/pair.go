@@ -0,0 +1,78 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"sort"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+// unpaired returns a violation for every call to the open function of the
+// given pair policy, that is not followed by a call to its close function.
+// Each violation is a decl for the calling function, with the open call as
+// its only call.
+func unpaired(decls map[string]graph.FuncDecl, policy Policy) []Decl {
+	var names []string
+	for name, decl := range decls {
+		if policy.Scope.allows(decl) && policy.covers(decl) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	isClose := func(call graph.FuncCall) bool {
+		return call.Name == policy.Pair.Close
+	}
+
+	var results []Decl
+
+	for _, name := range names {
+		decl := decls[name]
+
+		for index, call := range decl.Calls {
+			if call.Name != policy.Pair.Open || call.Deferred {
+				continue
+			}
+
+			if closed(decl, index, isClose) {
+				continue
+			}
+
+			results = append(results, Decl{
+				Name:     decl.Name,
+				Position: decl.Position,
+				Calls: []Call{
+					{
+						Position: call.Position,
+						Name:     call.Name,
+						Decl:     Decl{Name: call.Name, Position: decls[call.Name].Position},
+						Index:    index,
+					},
+				},
+			})
+		}
+	}
+
+	return results
+}
+
+// closed reports whether the call at the given index is followed by a closing
+// call. If the control flow of the function is unknown, any closing call that
+// is deferred, or appears later in the source, is accepted.
+func closed(decl graph.FuncDecl, index int, isClose func(graph.FuncCall) bool) bool {
+	if followed, known := decl.Followed(index, isClose); known {
+		return followed
+	}
+
+	for later, call := range decl.Calls {
+		if isClose(call) && (call.Deferred || later > index) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"github.com/joshdk/callcheck/graph"
+)
+
+// DefaultSinks lists the functions that end the normal flow of a program,
+// either by panicking or by exiting.
+var DefaultSinks = []string{
+	"panic",
+	"log.Fatal*",
+	"(*log.Logger).Fatal*",
+	"os.Exit",
+}
+
+// Exits lists the functions that end the program, or the calling goroutine,
+// without panicking, and so cannot be recovered from.
+var Exits = []string{
+	"log.Fatal*",
+	"(*log.Logger).Fatal*",
+	"os.Exit",
+	"runtime.Goexit",
+}
+
+// Panics returns a witness for every given root that can reach one of the
+// functions named by sinks, which may be globs. Each witness is the shortest
+// chain of calls from the root to a sink.
+//
+// A panic is stopped by any function that defers a call to recover, so chains
+// ending in a sink that panics, such as panic or log.Panicf, may not pass
+// through one. Sinks named by Exits cannot be recovered from.
+func Panics(decls map[string]graph.FuncDecl, roots []string, sinks []string) []Decl {
+	var (
+		patterns = make([]graph.Pattern, 0, len(sinks))
+		exits    = make([]graph.Pattern, 0, len(Exits))
+		recovers = graph.Recovers(decls)
+		results  []Decl
+	)
+
+	for _, sink := range sinks {
+		patterns = append(patterns, graph.NewPattern(sink))
+	}
+
+	for _, exit := range Exits {
+		exits = append(exits, graph.NewPattern(exit))
+	}
+
+	for _, root := range roots {
+		if steps := witness(decls, root, patterns, exits, recovers); steps != nil {
+			results = append(results, chain(decls, steps))
+		}
+	}
+
+	return results
+}
+
+// witness returns the shortest chain of calls from root to a sink, or nil if
+// no sink can be reached.
+func witness(decls map[string]graph.FuncDecl, root string, sinks []graph.Pattern, exits []graph.Pattern, recovers map[string]struct{}) []step {
+	// Functions are visited both inside and outside of the protection of a
+	// deferred recover, as sinks that exit remain reachable from inside.
+	type state struct {
+		name    string
+		guarded bool
+	}
+
+	type edge struct {
+		from state
+		step step
+	}
+
+	_, guarded := recovers[root]
+
+	var (
+		start    = state{root, guarded}
+		previous = map[state]edge{start: {}}
+		queue    = []state{start}
+	)
+
+	unwind := func(current state, last step) []step {
+		steps := []step{last}
+		for current != start {
+			back := previous[current]
+			steps = append([]step{back.step}, steps...)
+			current = back.from
+		}
+		return steps
+	}
+
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for index, call := range decls[current.name].Calls {
+			if matchesSink(sinks, call.Name) {
+				// A panic that is recovered is not a witness.
+				if current.guarded && !matchesSink(exits, call.Name) {
+					continue
+				}
+
+				return unwind(current, step{current.name, index})
+			}
+
+			if _, found := decls[call.Name]; !found {
+				continue
+			}
+
+			_, recovered := recovers[call.Name]
+			next := state{call.Name, current.guarded || recovered}
+
+			if _, found := previous[next]; found {
+				continue
+			}
+
+			previous[next] = edge{current, step{current.name, index}}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil
+}
+
+// matchesSink reports whether the named function matches any of the given
+// sinks, or exits.
+func matchesSink(sinks []graph.Pattern, name string) bool {
+	for _, sink := range sinks {
+		if sink.MatchName(name) {
+			return true
+		}
+	}
+	return false
+}
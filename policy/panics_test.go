@@ -0,0 +1,159 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+func TestPanics(t *testing.T) {
+	cg := map[string]graph.FuncDecl{
+		"lib.Parse": {
+			Name: "lib.Parse",
+			Calls: []graph.FuncCall{
+				{Name: "lib.parse"},
+			},
+		},
+		"lib.parse": {
+			Name: "lib.parse",
+			Calls: []graph.FuncCall{
+				{Name: "lib.check"},
+			},
+		},
+		"lib.check": {
+			Name: "lib.check",
+			Calls: []graph.FuncCall{
+				{Name: "panic"},
+			},
+		},
+		"lib.Safe": {
+			Name: "lib.Safe",
+			Calls: []graph.FuncCall{
+				{Name: "lib.parse"},
+				{Name: "recover", Deferred: true, Literal: true},
+			},
+		},
+		"lib.Handled": {
+			Name: "lib.Handled",
+			Calls: []graph.FuncCall{
+				{Name: "lib.guard", Deferred: true},
+				{Name: "lib.check"},
+			},
+		},
+		"lib.guard": {
+			Name: "lib.guard",
+			Calls: []graph.FuncCall{
+				{Name: "recover"},
+			},
+		},
+		"lib.Quit": {
+			Name: "lib.Quit",
+			Calls: []graph.FuncCall{
+				{Name: "recover", Deferred: true, Literal: true},
+				{Name: "lib.quit"},
+			},
+		},
+		"lib.quit": {
+			Name: "lib.quit",
+			Calls: []graph.FuncCall{
+				{Name: "log.Fatalf"},
+			},
+		},
+		"lib.Pure": {
+			Name: "lib.Pure",
+		},
+	}
+
+	roots := []string{"lib.Handled", "lib.Parse", "lib.Pure", "lib.Quit", "lib.Safe"}
+
+	expected := []Decl{
+		{
+			Name: "lib.Parse",
+			Calls: []Call{{
+				Name: "lib.parse",
+				Decl: Decl{
+					Name: "lib.parse",
+					Calls: []Call{{
+						Name: "lib.check",
+						Decl: Decl{
+							Name: "lib.check",
+							Calls: []Call{{
+								Name: "panic",
+								Decl: Decl{Name: "panic"},
+							}},
+						},
+					}},
+				},
+			}},
+		},
+		{
+			Name: "lib.Quit",
+			Calls: []Call{{
+				Name:  "lib.quit",
+				Index: 1,
+				Decl: Decl{
+					Name: "lib.quit",
+					Calls: []Call{{
+						Name: "log.Fatalf",
+						Decl: Decl{Name: "log.Fatalf"},
+					}},
+				},
+			}},
+		},
+	}
+
+	assert.Equal(t, expected, Panics(cg, roots, DefaultSinks))
+}
+
+func TestPanicsRecoverable(t *testing.T) {
+	cg := map[string]graph.FuncDecl{
+		"lib.Logged": {
+			Name: "lib.Logged",
+			Calls: []graph.FuncCall{
+				{Name: "recover", Deferred: true, Literal: true},
+				{Name: "log.Panicf"},
+			},
+		},
+		"lib.Exited": {
+			Name: "lib.Exited",
+			Calls: []graph.FuncCall{
+				{Name: "recover", Deferred: true, Literal: true},
+				{Name: "os.Exit"},
+			},
+		},
+		"lib.Unguarded": {
+			Name: "lib.Unguarded",
+			Calls: []graph.FuncCall{
+				{Name: "log.Panicf"},
+			},
+		},
+	}
+
+	roots := []string{"lib.Exited", "lib.Logged", "lib.Unguarded"}
+
+	expected := []Decl{
+		{
+			Name: "lib.Exited",
+			Calls: []Call{{
+				Name:  "os.Exit",
+				Index: 1,
+				Decl:  Decl{Name: "os.Exit"},
+			}},
+		},
+		{
+			Name: "lib.Unguarded",
+			Calls: []Call{{
+				Name: "log.Panicf",
+				Decl: Decl{Name: "log.Panicf"},
+			}},
+		},
+	}
+
+	assert.Equal(t, expected, Panics(cg, roots, []string{"log.Panic*", "os.Exit"}))
+}
@@ -4,13 +4,261 @@
 
 package policy
 
+import (
+	"github.com/joshdk/callcheck/graph"
+)
+
 type Policy struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description"`
 	Rule        *Node  `yaml:"rule"`
+
+	// Pair, if given instead of Rule, forbids functions that make a call
+	// which is not paired with a closing call.
+	Pair *Pair `yaml:"pair"`
+
+	// ForbidRecursion, if set instead of Rule, forbids every cycle of calls,
+	// including functions that call themselves.
+	ForbidRecursion bool `yaml:"forbid_recursion"`
+
+	// ForbidDeprecated, if set instead of Rule, forbids calls to functions
+	// whose doc comment carries a deprecation notice. Only functions that
+	// are declared in the packages being checked, and are not deprecated
+	// themselves, may act as the root of a violation.
+	ForbidDeprecated bool `yaml:"forbid_deprecated"`
+
+	// Transitive additionally forbids functions that reach a call to a
+	// deprecated function through a chain of calls, provided that every
+	// function in the chain may act as the root of a violation.
+	Transitive bool `yaml:"transitive"`
+
+	// Message explains why a violation is forbidden, Remediation explains
+	// how to fix it, and URL links to further documentation. Each is a
+	// text/template, rendered with the Fields of every violation.
+	Message     string `yaml:"message"`
+	Remediation string `yaml:"remediation"`
+	URL         string `yaml:"url"`
+
+	// Scope selects whether the policy applies to production code, test
+	// code, or both. Defaults to ScopeProd.
+	Scope Scope `yaml:"scope"`
+
+	// Include restricts the functions that may act as the root of a
+	// violation to those declared in a file or package matching one of these
+	// patterns. If empty, every function is included.
+	Include []string `yaml:"include"`
+
+	// Exclude prevents functions declared in a file or package matching one
+	// of these patterns from acting as the root of a violation.
+	Exclude []string `yaml:"exclude"`
+
+	// Intermediaries restricts the functions that a violation may pass
+	// through between its root and the forbidden call. If empty, a violation
+	// may pass through any function.
+	Intermediaries Patterns `yaml:"intermediaries"`
+}
+
+// Patterns selects functions by the file or package they are declared in.
+// Patterns are matched against both the import path of the function's
+// package, and the file it is declared in.
+type Patterns struct {
+	// Include restricts the selected functions to those matching one of
+	// these patterns. If empty, every function is included.
+	Include []string `yaml:"include"`
+
+	// Exclude prevents functions matching one of these patterns from being
+	// selected.
+	Exclude []string `yaml:"exclude"`
+}
+
+// selects reports whether the given function is selected by these patterns.
+func (patterns Patterns) selects(decl graph.FuncDecl) bool {
+	if len(patterns.Include) != 0 && !matchesAny(patterns.Include, decl) {
+		return false
+	}
+
+	return !matchesAny(patterns.Exclude, decl)
+}
+
+// covers reports whether the given function is selected by the include and
+// exclude patterns of this policy, and so may act as the root of a
+// violation.
+func (policy Policy) covers(decl graph.FuncDecl) bool {
+	return Patterns{Include: policy.Include, Exclude: policy.Exclude}.selects(decl)
+}
+
+func matchesAny(patterns []string, decl graph.FuncDecl) bool {
+	filename, _, _, _ := graph.ParsePosition(decl.Position)
+
+	for _, pattern := range patterns {
+		compiled := graph.NewPattern(pattern)
+		if compiled.MatchPackage(decl.Package) || (filename != "" && compiled.MatchFile(filename)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Pair requires that every call to Open is followed by a call to Close, made
+// by the same function on every path that returns normally, or deferred. Calls
+// are paired by function alone, regardless of their receivers or arguments.
+type Pair struct {
+	Open  string `yaml:"open"`
+	Close string `yaml:"close"`
+}
+
+// Order selects how the calls made by a function are ordered.
+type Order string
+
+const (
+	// OrderSource calls are ordered as they appear in the source of the
+	// calling function.
+	OrderSource Order = "source"
+
+	// OrderReachable calls are ordered if the later call may be made after
+	// the earlier call, along some path through the calling function.
+	OrderReachable Order = "reachable"
+
+	// OrderPostDominates calls are ordered if the later call is made on
+	// every path from the earlier call to the end of the calling function.
+	OrderPostDominates Order = "postdominates"
+)
+
+// Valid reports whether the order is one of the known orders, or empty.
+func (order Order) Valid() bool {
+	switch order {
+	case "", OrderSource, OrderReachable, OrderPostDominates:
+		return true
+	default:
+		return false
+	}
 }
 
+// Scope selects which functions a policy applies to.
+type Scope string
+
+const (
+	// ScopeProd policies may only be violated by functions declared outside
+	// of _test.go files, and violations may not pass through test functions.
+	ScopeProd Scope = "prod"
+
+	// ScopeTest policies may only be violated by functions declared in
+	// _test.go files.
+	ScopeTest Scope = "test"
+
+	// ScopeAll policies may be violated by any function.
+	ScopeAll Scope = "all"
+)
+
+// Valid reports whether the scope is one of the known scopes, or empty.
+func (scope Scope) Valid() bool {
+	switch scope {
+	case "", ScopeProd, ScopeTest, ScopeAll:
+		return true
+	default:
+		return false
+	}
+}
+
+// allows reports whether a function may act as the root of a violation under
+// this scope.
+func (scope Scope) allows(decl graph.FuncDecl) bool {
+	switch scope {
+	case ScopeAll:
+		return true
+	case ScopeTest:
+		return decl.Test
+	default:
+		return !decl.Test
+	}
+}
+
+// passes reports whether a violation under this scope may pass through the
+// given function.
+func (scope Scope) passes(decl graph.FuncDecl) bool {
+	return !decl.Test || scope == ScopeTest || scope == ScopeAll
+}
+
+// Node is a single function in a rule, along with the functions that it must
+// call, in order. A node may instead be a combinator, which has no name, and
+// combines its children in place of a single call.
 type Node struct {
+	// Name is the fully qualified name of a function. The name of the root
+	// node of a rule may contain wildcards, in which case every declared
+	// function that matches is treated as a separate root.
 	Name  string  `yaml:"name"`
 	Calls []*Node `yaml:"calls"`
+
+	// Ordered requires that the calls of this node are made in the order
+	// that they are listed. Defaults to true. Either way, each call must be
+	// made separately, so a call that is listed twice must be made twice.
+	Ordered *bool `yaml:"ordered"`
+
+	// Order selects what it means for one call to be made after another,
+	// when the calls of this node are ordered. Defaults to OrderSource.
+	Order Order `yaml:"order"`
+
+	// Direct requires that this function is called directly from the body
+	// of its parent, rather than through any other function.
+	Direct bool `yaml:"direct"`
+
+	// MaxDepth bounds the number of calls between the parent of this node
+	// and this function, so that a max depth of 1 is the same as Direct. A
+	// max depth of zero is unbounded.
+	MaxDepth int `yaml:"max_depth"`
+
+	// AnyOf matches if any one of its children is called.
+	AnyOf []*Node `yaml:"any_of"`
+
+	// AllOf matches if every one of its children is called, in any order.
+	AllOf []*Node `yaml:"all_of"`
+
+	// NoneOf matches if none of its children are called, anywhere beneath
+	// the enclosing function.
+	NoneOf []*Node `yaml:"none_of"`
+}
+
+// ordered reports whether the calls of this node must be made in order.
+func (node *Node) ordered() bool {
+	return node.Ordered == nil || *node.Ordered
+}
+
+// depth returns the maximum number of calls between the parent of this node
+// and this function, or zero if unbounded.
+func (node *Node) depth() int {
+	if node.Direct {
+		return 1
+	}
+	return node.MaxDepth
+}
+
+// Combinator reports whether this node combines its children, rather than
+// naming a single function.
+func (node *Node) Combinator() bool {
+	return len(node.AnyOf) != 0 || len(node.AllOf) != 0 || len(node.NoneOf) != 0
+}
+
+// Matches reports whether the given function name matches this node.
+func (node *Node) Matches(name string) bool {
+	if graph.IsGlob(node.Name) {
+		return graph.NewPattern(node.Name).MatchName(name)
+	}
+	return node.Name == name
+}
+
+// Walk calls fn for the given node and every node beneath it, in depth-first
+// order.
+func (node *Node) Walk(fn func(*Node)) {
+	if node == nil {
+		return
+	}
+
+	fn(node)
+
+	for _, children := range [][]*Node{node.Calls, node.AnyOf, node.AllOf, node.NoneOf} {
+		for _, child := range children {
+			child.Walk(fn)
+		}
+	}
 }
@@ -394,6 +394,1104 @@ func TestMatch(t *testing.T) {
 				},
 			},
 		},
+
+		// Policy that matches reading the environment, then running a command
+		{
+			name: "forbid-env-exec",
+			policy: Policy{
+				Name: "forbid-env-exec",
+				Rule: &Node{
+					Name: "main",
+					Calls: []*Node{
+						{
+							AnyOf: []*Node{
+								{Name: "os.Getenv"},
+								{Name: "os.LookupEnv"},
+							},
+						},
+						{Name: "exec.Command"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > getenv, exec",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "os.Getenv"},
+								{Name: "exec.Command"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lookupenv, run > exec",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "os.LookupEnv"},
+								{Name: "run"},
+							},
+						},
+						"run": {
+							Name: "run",
+							Calls: []graph.FuncCall{
+								{Name: "exec.Command"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > exec, getenv",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "exec.Command"},
+								{Name: "os.Getenv"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > exec",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "exec.Command"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches reading a secret and logging, in either order
+		{
+			name: "forbid-secret-log",
+			policy: Policy{
+				Name: "forbid-secret-log",
+				Rule: &Node{
+					Name: "main",
+					Calls: []*Node{
+						{
+							AllOf: []*Node{
+								{Name: "secret"},
+								{Name: "log"},
+							},
+						},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > secret, log",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "secret"},
+								{Name: "log"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > log, secret",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "log"},
+								{Name: "secret"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > secret",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "secret"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches reading a secret and logging, as unordered calls
+		{
+			name: "forbid-secret-log-unordered",
+			policy: Policy{
+				Name: "forbid-secret-log-unordered",
+				Rule: &Node{
+					Name:    "main",
+					Ordered: new(bool),
+					Calls: []*Node{
+						{Name: "secret"},
+						{Name: "log"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > secret, log",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "secret"},
+								{Name: "log"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > log, secret",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "log"},
+								{Name: "secret"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > log",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "log"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches locking twice, where each repeated call must be
+		// made separately
+		{
+			name: "forbid-double-lock",
+			policy: Policy{
+				Name: "forbid-double-lock",
+				Rule: &Node{
+					Name: "main",
+					Calls: []*Node{
+						{Name: "lock"},
+						{Name: "lock"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > lock",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "lock"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lock, lock",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "lock"},
+								{Name: "lock"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > acquire > lock, lock",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "acquire"},
+							},
+						},
+						"acquire": {
+							Name: "acquire",
+							Calls: []graph.FuncCall{
+								{Name: "lock"},
+								{Name: "lock"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches locking twice, in any order
+		{
+			name: "forbid-double-lock-unordered",
+			policy: Policy{
+				Name: "forbid-double-lock-unordered",
+				Rule: &Node{
+					Name:    "main",
+					Ordered: new(bool),
+					Calls: []*Node{
+						{Name: "lock"},
+						{Name: "lock"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > lock",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "lock"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lock, lock",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "lock"},
+								{Name: "lock"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches handlers that directly execute queries
+		{
+			name: "forbid-direct-exec",
+			policy: Policy{
+				Name: "forbid-direct-exec",
+				Rule: &Node{
+					Name: "handler",
+					Calls: []*Node{
+						{Name: "db.Exec", Direct: true},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "handler > exec",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"handler": {
+							Name: "handler",
+							Calls: []graph.FuncCall{
+								{Name: "db.Exec"},
+							},
+						},
+					},
+				},
+				{
+					name:    "handler > store > exec",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"handler": {
+							Name: "handler",
+							Calls: []graph.FuncCall{
+								{Name: "store"},
+							},
+						},
+						"store": {
+							Name: "store",
+							Calls: []graph.FuncCall{
+								{Name: "db.Exec"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches handlers that execute queries within two calls
+		{
+			name: "forbid-shallow-exec",
+			policy: Policy{
+				Name: "forbid-shallow-exec",
+				Rule: &Node{
+					Name: "handler",
+					Calls: []*Node{
+						{Name: "db.Exec", MaxDepth: 2},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "handler > store > exec",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"handler": {
+							Name: "handler",
+							Calls: []graph.FuncCall{
+								{Name: "store"},
+							},
+						},
+						"store": {
+							Name: "store",
+							Calls: []graph.FuncCall{
+								{Name: "db.Exec"},
+							},
+						},
+					},
+				},
+				{
+					name:    "handler > store > tx > exec",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"handler": {
+							Name: "handler",
+							Calls: []graph.FuncCall{
+								{Name: "store"},
+							},
+						},
+						"store": {
+							Name: "store",
+							Calls: []graph.FuncCall{
+								{Name: "tx"},
+							},
+						},
+						"tx": {
+							Name: "tx",
+							Calls: []graph.FuncCall{
+								{Name: "db.Exec"},
+							},
+						},
+					},
+				},
+				{
+					name:    "handler > deep > tx > exec, handler > tx > exec",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"handler": {
+							Name: "handler",
+							Calls: []graph.FuncCall{
+								{Name: "deep"},
+								{Name: "tx"},
+							},
+						},
+						"deep": {
+							Name: "deep",
+							Calls: []graph.FuncCall{
+								{Name: "tx"},
+							},
+						},
+						"tx": {
+							Name: "tx",
+							Calls: []graph.FuncCall{
+								{Name: "db.Exec"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches unlocking before locking, anywhere in a loop
+		{
+			name: "forbid-unlock-lock",
+			policy: Policy{
+				Name: "forbid-unlock-lock",
+				Rule: &Node{
+					Name:  "main",
+					Order: OrderReachable,
+					Calls: []*Node{
+						{Name: "unlock"},
+						{Name: "lock"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > loop { lock, unlock }",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name:   "main",
+							Blocks: [][]int{{1}, {1, 2}, {}},
+							Calls: []graph.FuncCall{
+								{Name: "lock", Block: 1},
+								{Name: "unlock", Block: 1},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lock, unlock",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name:   "main",
+							Blocks: [][]int{{}},
+							Calls: []graph.FuncCall{
+								{Name: "lock", Block: 0},
+								{Name: "unlock", Block: 0},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches locking, followed by unlocking on every path
+		{
+			name: "forbid-lock-unlock-always",
+			policy: Policy{
+				Name: "forbid-lock-unlock-always",
+				Rule: &Node{
+					Name:  "main",
+					Order: OrderPostDominates,
+					Calls: []*Node{
+						{Name: "lock"},
+						{Name: "unlock"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > lock, if { unlock }",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name:   "main",
+							Blocks: [][]int{{1, 2}, {2}, {}},
+							Calls: []graph.FuncCall{
+								{Name: "lock", Block: 0},
+								{Name: "unlock", Block: 1},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lock, if {} unlock",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name:   "main",
+							Blocks: [][]int{{1, 2}, {2}, {}},
+							Calls: []graph.FuncCall{
+								{Name: "lock", Block: 0},
+								{Name: "unlock", Block: 2},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lock, unlock without control flow",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "lock"},
+								{Name: "unlock"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches locks without a paired unlock
+		{
+			name: "forbid-unpaired-lock",
+			policy: Policy{
+				Name: "forbid-unpaired-lock",
+				Pair: &Pair{
+					Open:  "lock",
+					Close: "unlock",
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > lock",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "lock"},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lock, defer unlock",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name: "main",
+							Calls: []graph.FuncCall{
+								{Name: "lock"},
+								{Name: "unlock", Deferred: true},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lock, if { return } unlock",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name:    "main",
+							Blocks:  [][]int{{1, 2}, {}, {}},
+							Returns: []int{1, 2},
+							Calls: []graph.FuncCall{
+								{Name: "lock", Block: 0},
+								{Name: "unlock", Block: 2},
+							},
+						},
+					},
+				},
+				{
+					name:    "main > lock, if { panic } unlock",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name:    "main",
+							Blocks:  [][]int{{1, 2}, {}, {}},
+							Returns: []int{2},
+							Calls: []graph.FuncCall{
+								{Name: "lock", Block: 0},
+								{Name: "panic", Block: 1},
+								{Name: "unlock", Block: 2},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches any recursion within package a
+		{
+			name: "forbid-recursion",
+			policy: Policy{
+				Name:            "forbid-recursion",
+				ForbidRecursion: true,
+				Include:         []string{"a"},
+			},
+			tests: []test{
+				{
+					name:    "a.main > a.fact > a.fact",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"a.main": {
+							Name:    "a.main",
+							Package: "a",
+							Calls: []graph.FuncCall{
+								{Name: "a.fact"},
+							},
+						},
+						"a.fact": {
+							Name:    "a.fact",
+							Package: "a",
+							Calls: []graph.FuncCall{
+								{Name: "a.fact"},
+							},
+						},
+					},
+				},
+				{
+					name:    "a.even > b.odd > a.even",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"a.even": {
+							Name:    "a.even",
+							Package: "a",
+							Calls: []graph.FuncCall{
+								{Name: "b.odd"},
+							},
+						},
+						"b.odd": {
+							Name:    "b.odd",
+							Package: "b",
+							Calls: []graph.FuncCall{
+								{Name: "a.even"},
+							},
+						},
+					},
+				},
+				{
+					name:    "b.fact > b.fact",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"b.fact": {
+							Name:    "b.fact",
+							Package: "b",
+							Calls: []graph.FuncCall{
+								{Name: "b.fact"},
+							},
+						},
+					},
+				},
+				{
+					name:    "a.main > a.run",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"a.main": {
+							Name:    "a.main",
+							Package: "a",
+							Calls: []graph.FuncCall{
+								{Name: "a.run"},
+							},
+						},
+						"a.run": {
+							Name:    "a.run",
+							Package: "a",
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches direct calls to deprecated functions
+		{
+			name: "forbid-deprecated",
+			policy: Policy{
+				Name:             "forbid-deprecated",
+				ForbidDeprecated: true,
+			},
+			tests: []test{
+				{
+					name:    "main > ioutil.ReadAll",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name:    "main",
+							Initial: true,
+							Calls: []graph.FuncCall{
+								{Name: "ioutil.ReadAll"},
+							},
+						},
+						"ioutil.ReadAll": {
+							Name:       "ioutil.ReadAll",
+							Deprecated: "As of Go 1.16, this function simply calls io.ReadAll.",
+						},
+					},
+				},
+				{
+					name:    "read > ioutil.ReadAll",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"read": {
+							Name:       "read",
+							Initial:    true,
+							Deprecated: "Use io.ReadAll instead.",
+							Calls: []graph.FuncCall{
+								{Name: "ioutil.ReadAll"},
+							},
+						},
+						"ioutil.ReadAll": {
+							Name:       "ioutil.ReadAll",
+							Deprecated: "As of Go 1.16, this function simply calls io.ReadAll.",
+						},
+					},
+				},
+				{
+					name:    "dep.Read > ioutil.ReadAll",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"dep.Read": {
+							Name: "dep.Read",
+							Calls: []graph.FuncCall{
+								{Name: "ioutil.ReadAll"},
+							},
+						},
+						"ioutil.ReadAll": {
+							Name:       "ioutil.ReadAll",
+							Deprecated: "As of Go 1.16, this function simply calls io.ReadAll.",
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches handlers that never check authorization
+		{
+			name: "forbid-unauthorized",
+			policy: Policy{
+				Name: "forbid-unauthorized",
+				Rule: &Node{
+					Name: "handler",
+					Calls: []*Node{
+						{Name: "db.Exec"},
+						{
+							NoneOf: []*Node{
+								{Name: "auth.Check"},
+							},
+						},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "handler > exec",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"handler": {
+							Name: "handler",
+							Calls: []graph.FuncCall{
+								{Name: "db.Exec"},
+							},
+						},
+					},
+				},
+				{
+					name:    "handler > check, exec",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"handler": {
+							Name: "handler",
+							Calls: []graph.FuncCall{
+								{Name: "authorize"},
+								{Name: "db.Exec"},
+							},
+						},
+						"authorize": {
+							Name: "authorize",
+							Calls: []graph.FuncCall{
+								{Name: "auth.Check"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches calls to panic() from within a single package
+		{
+			name: "forbid-api-panic",
+			policy: Policy{
+				Name:    "forbid-api-panic",
+				Include: []string{".../internal/api/..."},
+				Exclude: []string{"*_gen.go"},
+				Rule: &Node{
+					Name: "a/internal/api.*",
+					Calls: []*Node{
+						{Name: "panic"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "api > panic",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"a/internal/api.Serve": {
+							Name:     "a/internal/api.Serve",
+							Package:  "a/internal/api",
+							Initial:  true,
+							Position: "/src/a/internal/api/serve.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "panic"},
+							},
+						},
+					},
+				},
+				{
+					name:    "generated api > panic",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"a/internal/api.Serve": {
+							Name:     "a/internal/api.Serve",
+							Package:  "a/internal/api",
+							Initial:  true,
+							Position: "/src/a/internal/api/serve_gen.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "panic"},
+							},
+						},
+					},
+				},
+				{
+					name:    "api > util > panic",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"a/internal/api.Serve": {
+							Name:     "a/internal/api.Serve",
+							Package:  "a/internal/api",
+							Initial:  true,
+							Position: "/src/a/internal/api/serve.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "a/util.Must"},
+							},
+						},
+						"a/util.Must": {
+							Name:     "a/util.Must",
+							Package:  "a/util",
+							Initial:  true,
+							Position: "/src/a/util/must.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "panic"},
+							},
+						},
+					},
+				},
+				{
+					name:    "api > api/v1 > panic",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"a/internal/api.Serve": {
+							Name:     "a/internal/api.Serve",
+							Package:  "a/internal/api",
+							Initial:  true,
+							Position: "/src/a/internal/api/serve.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "a/internal/api/v1.Handle"},
+							},
+						},
+						"a/internal/api/v1.Handle": {
+							Name:     "a/internal/api/v1.Handle",
+							Package:  "a/internal/api/v1",
+							Initial:  true,
+							Position: "/src/a/internal/api/v1/handle.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "panic"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches calls to panic() from the api package, unless
+		// they are made from the vendored packages
+		{
+			name: "forbid-api-panic-outside-vendor",
+			policy: Policy{
+				Name:    "forbid-api-panic-outside-vendor",
+				Include: []string{"a/internal/api"},
+				Intermediaries: Patterns{
+					Exclude: []string{"a/vendor/..."},
+				},
+				Rule: &Node{
+					Name: "a/internal/api.*",
+					Calls: []*Node{
+						{Name: "panic"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "api > util > panic",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"a/internal/api.Serve": {
+							Name:     "a/internal/api.Serve",
+							Package:  "a/internal/api",
+							Initial:  true,
+							Position: "/src/a/internal/api/serve.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "a/util.Must"},
+							},
+						},
+						"a/util.Must": {
+							Name:     "a/util.Must",
+							Package:  "a/util",
+							Initial:  true,
+							Position: "/src/a/util/must.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "panic"},
+							},
+						},
+					},
+				},
+				{
+					name:    "api > vendor > panic",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"a/internal/api.Serve": {
+							Name:     "a/internal/api.Serve",
+							Package:  "a/internal/api",
+							Initial:  true,
+							Position: "/src/a/internal/api/serve.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "a/vendor/errors.Must"},
+							},
+						},
+						"a/vendor/errors.Must": {
+							Name:     "a/vendor/errors.Must",
+							Package:  "a/vendor/errors",
+							Initial:  true,
+							Position: "/src/a/vendor/errors/must.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "panic"},
+							},
+						},
+					},
+				},
+				{
+					name:    "util > panic",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"a/util.Must": {
+							Name:     "a/util.Must",
+							Package:  "a/util",
+							Initial:  true,
+							Position: "/src/a/util/must.go:1:1",
+							Calls: []graph.FuncCall{
+								{Name: "panic"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy that matches calls to os.Exit() from any function in a package
+		{
+			name: "forbid-package-exit",
+			policy: Policy{
+				Name: "forbid-package-exit",
+				Rule: &Node{
+					Name: "a.*",
+					Calls: []*Node{
+						{Name: "os.Exit"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "nil graph",
+					matches: false,
+					graph:   nil,
+				},
+				{
+					name:    "a.Run > os.Exit",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"a.Run": {
+							Name:    "a.Run",
+							Package: "a",
+							Initial: true,
+							Calls: []graph.FuncCall{
+								{Name: "os.Exit"},
+							},
+						},
+					},
+				},
+				{
+					name:    "b.Run > os.Exit",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"b.Run": {
+							Name:    "b.Run",
+							Package: "b",
+							Initial: true,
+							Calls: []graph.FuncCall{
+								{Name: "os.Exit"},
+							},
+						},
+					},
+				},
+				{
+					name:    "b.Run > a.exit > os.Exit",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"b.Run": {
+							Name:    "b.Run",
+							Package: "b",
+							Initial: true,
+							Calls: []graph.FuncCall{
+								{Name: "a.exit"},
+							},
+						},
+						"a.exit": {
+							Name:    "a.exit",
+							Package: "a",
+							Initial: true,
+							Calls: []graph.FuncCall{
+								{Name: "os.Exit"},
+							},
+						},
+					},
+				},
+				{
+					name:    "dependency a.Run > os.Exit",
+					matches: false,
+					graph: map[string]graph.FuncDecl{
+						"a.Run": {
+							Name:    "a.Run",
+							Package: "a",
+							Calls: []graph.FuncCall{
+								{Name: "os.Exit"},
+							},
+						},
+					},
+				},
+			},
+		},
+
+		// Policy whose wildcard root excludes the function that it forbids
+		{
+			name: "forbid-weak-hash",
+			policy: Policy{
+				Name:    "forbid-weak-hash",
+				Exclude: []string{"crypto/..."},
+				Rule: &Node{
+					Name: "*",
+					Calls: []*Node{
+						{Name: "crypto/md5.Sum"},
+					},
+				},
+			},
+			tests: []test{
+				{
+					name:    "main > crypto/md5.Sum",
+					matches: true,
+					graph: map[string]graph.FuncDecl{
+						"main": {
+							Name:    "main",
+							Package: "main",
+							Initial: true,
+							Calls: []graph.FuncCall{
+								{Name: "crypto/md5.Sum"},
+							},
+						},
+						"crypto/md5.Sum": {
+							Name:    "crypto/md5.Sum",
+							Package: "crypto/md5",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for suiteIndex, suite := range suites {
@@ -0,0 +1,120 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"github.com/joshdk/callcheck/graph"
+)
+
+// Cycle returns a decl tree for the given component of mutually recursive
+// functions, as found by graph.Cycles. The decl starts at the first function
+// of the component, and follows the shortest path of calls through the
+// component back to that function.
+func Cycle(decls map[string]graph.FuncDecl, component []string) Decl {
+	return cycle(decls, component, component[0])
+}
+
+// recursion returns a violation for every cycle of calls in the given call
+// graph. Each violation starts at the first function of its cycle that the
+// policy covers, and that its scope allows.
+func recursion(decls map[string]graph.FuncDecl, policy Policy) []Decl {
+	var results []Decl
+
+	for _, component := range graph.Cycles(decls) {
+		for _, name := range component {
+			if decl := decls[name]; policy.Scope.allows(decl) && policy.covers(decl) {
+				results = append(results, cycle(decls, component, name))
+				break
+			}
+		}
+	}
+
+	return results
+}
+
+// cycle returns the shortest path of calls from the function named start back
+// to itself, that only passes through the given functions.
+func cycle(decls map[string]graph.FuncDecl, component []string, start string) Decl {
+	members := make(map[string]struct{}, len(component))
+	for _, name := range component {
+		members[name] = struct{}{}
+	}
+
+	var (
+		previous = make(map[string]step)
+		queue    = []string{start}
+		last     *step
+	)
+
+search:
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for index, call := range decls[current].Calls {
+			if call.Name == start {
+				last = &step{current, index}
+				break search
+			}
+
+			if _, found := members[call.Name]; !found {
+				continue
+			}
+
+			if _, found := previous[call.Name]; found {
+				continue
+			}
+
+			previous[call.Name] = step{current, index}
+			queue = append(queue, call.Name)
+		}
+	}
+
+	if last == nil {
+		return Decl{Name: start, Position: decls[start].Position}
+	}
+
+	// Unwind the path backwards, from the call that closes the cycle.
+	steps := []step{*last}
+	for steps[0].caller != start {
+		steps = append([]step{previous[steps[0].caller]}, steps...)
+	}
+
+	return chain(decls, steps)
+}
+
+// step is a single call, made by the named function, at the given index of
+// its calls.
+type step struct {
+	caller string
+	index  int
+}
+
+// chain returns a linear decl tree that follows the given calls in order,
+// where each call is made by the function called in the previous step.
+func chain(decls map[string]graph.FuncDecl, steps []step) Decl {
+	last := decls[steps[len(steps)-1].caller].Calls[steps[len(steps)-1].index]
+	decl := Decl{Name: last.Name, Position: decls[last.Name].Position}
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		caller := decls[steps[i].caller]
+		call := caller.Calls[steps[i].index]
+
+		decl = Decl{
+			Name:     steps[i].caller,
+			Position: caller.Position,
+			Calls: []Call{
+				{
+					Position: call.Position,
+					Name:     call.Name,
+					Decl:     decl,
+					Index:    steps[i].index,
+				},
+			},
+		}
+	}
+
+	return decl
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joshdk/callcheck/graph"
+)
+
+func TestCycle(t *testing.T) {
+	cg := map[string]graph.FuncDecl{
+		"a": {
+			Name:     "a",
+			Position: "main.go:1:6",
+			Calls: []graph.FuncCall{
+				{Name: "b", Position: "main.go:2:2"},
+				{Name: "c", Position: "main.go:3:2"},
+			},
+		},
+		"b": {
+			Name:     "b",
+			Position: "main.go:6:6",
+			Calls: []graph.FuncCall{
+				{Name: "c", Position: "main.go:7:2"},
+			},
+		},
+		"c": {
+			Name:     "c",
+			Position: "main.go:10:6",
+			Calls: []graph.FuncCall{
+				{Name: "b", Position: "main.go:11:2"},
+				{Name: "a", Position: "main.go:12:2"},
+			},
+		},
+	}
+
+	expected := Decl{
+		Name:     "a",
+		Position: "main.go:1:6",
+		Calls: []Call{
+			{
+				Name:     "c",
+				Position: "main.go:3:2",
+				Index:    1,
+				Decl: Decl{
+					Name:     "c",
+					Position: "main.go:10:6",
+					Calls: []Call{
+						{
+							Name:     "a",
+							Position: "main.go:12:2",
+							Index:    1,
+							Decl: Decl{
+								Name:     "a",
+								Position: "main.go:1:6",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, Cycle(cg, []string{"a", "b", "c"}))
+}
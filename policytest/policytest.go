@@ -0,0 +1,281 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+// Package policytest provides utilities for testing policies against small
+// Go packages, in the style of golang.org/x/tools/go/analysis/analysistest.
+//
+// Packages are loaded from a GOPATH style testdata directory, such that the
+// package "a" is located in dir/src/a. Every violation found is expected to be
+// matched by a comment of the form:
+//
+//	run() // want "policy-name"
+//
+// placed on the line where the violation begins. For rules that call other
+// functions, that is the line of the first call made by the root function of
+// the rule. For rules consisting of a single function, it is the line where
+// that function is declared. Multiple policy names may be given in a single
+// comment.
+package policytest
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/scanner"
+
+	"golang.org/x/tools/go/loader"
+
+	"github.com/joshdk/callcheck/graph"
+	"github.com/joshdk/callcheck/policy"
+)
+
+// Testing is the subset of *testing.T used by Run.
+type Testing interface {
+	Errorf(format string, args ...interface{})
+}
+
+// TestData returns the absolute path of the testdata directory in the current
+// working directory.
+func TestData() string {
+	testdata, err := filepath.Abs("testdata")
+	if err != nil {
+		panic(err)
+	}
+	return testdata
+}
+
+// Run loads the packages matching patterns from the testdata directory dir,
+// checks them against each of the given policies, and reports every
+// violation that does not match an expectation, and every expectation that
+// does not match a violation.
+func Run(t Testing, dir string, policies []policy.Policy, patterns ...string) {
+	program, err := load(dir, patterns)
+	if err != nil {
+		t.Errorf("loading packages: %v", err)
+		return
+	}
+
+	decls, err := graph.Program(program)
+	if err != nil {
+		t.Errorf("building call graph: %v", err)
+		return
+	}
+
+	expected, err := expectations(program)
+	if err != nil {
+		t.Errorf("parsing expectations: %v", err)
+		return
+	}
+
+	actual := make(map[expectation]struct{})
+
+	for _, forbidden := range policies {
+		for _, violation := range policy.MatchingPaths(decls, forbidden) {
+			actual[expectation{position(violation), forbidden.Name}] = struct{}{}
+		}
+	}
+
+	for _, want := range sortedExpectations(actual) {
+		if _, found := expected[want]; !found {
+			t.Errorf("%s: unexpected violation of %q", want.position, want.name)
+		}
+	}
+
+	for _, want := range sortedExpectations(expected) {
+		if _, found := actual[want]; !found {
+			t.Errorf("%s: expected violation of %q was not found", want.position, want.name)
+		}
+	}
+}
+
+// Packages returns the import path of every package found in the testdata
+// directory dir.
+func Packages(dir string) ([]string, error) {
+	var (
+		src      = filepath.Join(dir, "src")
+		seen     = make(map[string]struct{})
+		packages []string
+	)
+
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		pkg, err := filepath.Rel(src, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		pkg = filepath.ToSlash(pkg)
+		if _, found := seen[pkg]; !found {
+			seen[pkg] = struct{}{}
+			packages = append(packages, pkg)
+		}
+
+		return nil
+	})
+
+	return packages, err
+}
+
+// load parses and type checks the packages matching patterns from the
+// testdata directory dir.
+func load(dir string, patterns []string) (*loader.Program, error) {
+	all, err := Packages(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxt := build.Default
+	ctxt.GOPATH = dir
+
+	// Setting a custom JoinPath causes go/build to ignore any enclosing Go
+	// module, and resolve packages using GOPATH alone.
+	ctxt.JoinPath = filepath.Join
+
+	cfg := loader.Config{
+		Build:      &ctxt,
+		Cwd:        filepath.Join(dir, "src"),
+		ParserMode: parser.ParseComments,
+	}
+
+	var matched bool
+
+	for _, pkg := range all {
+		if matchAny(pkg, patterns) {
+			cfg.Import(pkg)
+			matched = true
+		}
+	}
+
+	if !matched {
+		return nil, fmt.Errorf("no packages in %s match %s", dir, strings.Join(patterns, " "))
+	}
+
+	return cfg.Load()
+}
+
+// matchAny reports whether the import path pkg matches any of the given
+// patterns. A pattern ending in "/..." also matches every package beneath it.
+// An empty list of patterns matches every package.
+func matchAny(pkg string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		switch {
+		case pattern == "..." || pattern == pkg:
+			return true
+		case strings.HasSuffix(pattern, "/..."):
+			prefix := strings.TrimSuffix(pattern, "/...")
+			if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// expectation is a single policy violation found at, or expected at, a
+// source line.
+type expectation struct {
+	position string
+	name     string
+}
+
+var wantRegex = regexp.MustCompile(`^//\s*want\s+(.*)$`)
+
+// expectations parses every want comment found in the initial packages of
+// the given program.
+func expectations(program *loader.Program) (map[expectation]struct{}, error) {
+	expected := make(map[expectation]struct{})
+
+	for _, pkgInfo := range program.InitialPackages() {
+		for _, astFile := range pkgInfo.Files {
+			for _, group := range astFile.Comments {
+				for _, comment := range group.List {
+					if err := parseComment(program, comment, expected); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	return expected, nil
+}
+
+func parseComment(program *loader.Program, comment *ast.Comment, expected map[expectation]struct{}) error {
+	matches := wantRegex.FindStringSubmatch(comment.Text)
+	if matches == nil {
+		return nil
+	}
+
+	pos := program.Fset.Position(comment.Pos())
+
+	var sc scanner.Scanner
+	sc.Init(strings.NewReader(matches[1]))
+	sc.Mode = scanner.ScanStrings | scanner.ScanRawStrings
+	sc.Error = func(*scanner.Scanner, string) {}
+
+	for tok := sc.Scan(); tok != scanner.EOF; tok = sc.Scan() {
+		if tok != scanner.String && tok != scanner.RawString {
+			return fmt.Errorf("%s: want comment must contain only quoted policy names", pos)
+		}
+
+		name, err := strconv.Unquote(sc.TokenText())
+		if err != nil {
+			return fmt.Errorf("%s: %v", pos, err)
+		}
+
+		expected[expectation{fmt.Sprintf("%s:%d", pos.Filename, pos.Line), name}] = struct{}{}
+	}
+
+	return nil
+}
+
+// position returns the file and line where the given violation begins.
+func position(violation policy.Decl) string {
+	pos := violation.Position
+	if len(violation.Calls) != 0 {
+		pos = violation.Calls[0].Position
+	}
+
+	// Discard the column, as expectations are only line accurate.
+	if filename, line, _, ok := graph.ParsePosition(pos); ok {
+		return fmt.Sprintf("%s:%d", filename, line)
+	}
+
+	return pos
+}
+
+func sortedExpectations(expectations map[expectation]struct{}) []expectation {
+	sorted := make([]expectation, 0, len(expectations))
+	for want := range expectations {
+		sorted = append(sorted, want)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].position != sorted[j].position {
+			return sorted[i].position < sorted[j].position
+		}
+		return sorted[i].name < sorted[j].name
+	})
+
+	return sorted
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Josh Komoroske. All rights reserved.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE.txt file.
+
+package policytest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/joshdk/callcheck/policy"
+)
+
+type recorder []string
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	*r = append(*r, fmt.Sprintf(format, args...))
+}
+
+func TestRun(t *testing.T) {
+
+	tests := []struct {
+		title    string
+		policies []policy.Policy
+		errors   []string
+	}{
+		{
+			title: "all expectations met",
+			policies: []policy.Policy{
+				{
+					Name: "no-exit",
+					Rule: &policy.Node{
+						Name:  "a.main",
+						Calls: []*policy.Node{{Name: "os.Exit"}},
+					},
+				},
+				{
+					Name: "no-unused",
+					Rule: &policy.Node{Name: "a.unused"},
+				},
+			},
+		},
+		{
+			title: "missing and unexpected violations",
+			policies: []policy.Policy{
+				{
+					Name: "no-unused",
+					Rule: &policy.Node{Name: "a.unused"},
+				},
+				{
+					Name: "no-setup",
+					Rule: &policy.Node{
+						Name:  "a.main",
+						Calls: []*policy.Node{{Name: "a.setup"}},
+					},
+				},
+			},
+			errors: []string{
+				`a.go:8: unexpected violation of "no-setup"`,
+				`a.go:10: expected violation of "no-exit" was not found`,
+				`a.go:12: expected violation of "no-exit" was not found`,
+			},
+		},
+	}
+
+	for index, test := range tests {
+		name := fmt.Sprintf("#%d - %s", index, test.title)
+
+		t.Run(name, func(t *testing.T) {
+			var errors recorder
+
+			Run(&errors, TestData(), test.policies, "a")
+
+			// Trim absolute paths, so that errors are comparable.
+			for index, err := range errors {
+				errors[index] = err[strings.LastIndex(err, "/")+1:]
+			}
+
+			assert.Equal(t, len(test.errors), len(errors))
+			for _, err := range test.errors {
+				assert.Contains(t, errors, err)
+			}
+		})
+	}
+}
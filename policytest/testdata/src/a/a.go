@@ -0,0 +1,27 @@
+package a
+
+import (
+	"os"
+)
+
+func main() {
+	setup()
+	if len(os.Args) > 1 {
+		run() // want "no-exit"
+	}
+	cleanup() // want "no-exit"
+}
+
+func setup() {}
+
+func run() {
+	os.Exit(1)
+}
+
+func cleanup() {
+	os.Exit(0)
+}
+
+func unused() { // want "no-unused"
+	os.Exit(2)
+}